@@ -0,0 +1,432 @@
+package ginger
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/iancoleman/strcase"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	tag_default = "default"
+	tag_env     = "env"
+	tag_flag    = "flag"
+)
+
+// ConfigOption configures LoadConfig
+type ConfigOption func(*configLoader)
+
+type configLoader struct {
+	file      string
+	envPrefix string
+	args      []string
+	withFlags bool
+	validator *Validator
+}
+
+// WithConfigFile reads a .yaml, .yml, .json or .toml file, a missing file is an error
+func WithConfigFile(path string) ConfigOption {
+	return func(l *configLoader) {
+		l.file = path
+	}
+}
+
+// WithEnvPrefix prepends prefix to the environment variable names, e.g. APP_ for APP_ADDR
+func WithEnvPrefix(prefix string) ConfigOption {
+	return func(l *configLoader) {
+		l.envPrefix = prefix
+	}
+}
+
+// WithFlags parses the fields tagged with flag from args, usually os.Args[1:]
+func WithFlags(args []string) ConfigOption {
+	return func(l *configLoader) {
+		l.args = args
+		l.withFlags = true
+	}
+}
+
+// WithConfigValidator validates the config with v, e.g. to use the rules registered on
+// Engine.Validator, instead of a validator with the built-in rules only
+func WithConfigValidator(v *Validator) ConfigOption {
+	return func(l *configLoader) {
+		l.validator = v
+	}
+}
+
+// LoadConfig fills a T from, in increasing precedence, the default tags, the config file,
+// the environment variables and the flags, then validates it with the binding tags.
+// The environment variable of a field is its env tag, or its name in SCREAMING_SNAKE_CASE,
+// prefixed by the ones of its parent structs.
+func LoadConfig[T any](opts ...ConfigOption) (*T, error) {
+	loader := &configLoader{}
+	for _, opt := range opts {
+		opt(loader)
+	}
+
+	config := new(T)
+	value := reflect.ValueOf(config).Elem()
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: %T is not a struct", *config)
+	}
+
+	err := loader.loadDefaults(value)
+	if err != nil {
+		return nil, err
+	}
+	if loader.file != "" {
+		err = loader.loadFile(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = loader.loadEnv(value, loader.envPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if loader.withFlags {
+		err = loader.loadFlags(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	v := loader.validator
+	if v == nil {
+		v = validatorOf(nil)
+	}
+	err = v.ValidateStruct(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (l *configLoader) loadDefaults(value reflect.Value) error {
+	return walkConfig(value, "", func(field reflect.StructField, v func() reflect.Value, _ string) error {
+		def, ok := field.Tag.Lookup(tag_default)
+		if !ok {
+			return nil
+		}
+		err := setFromString(v(), def)
+		if err != nil {
+			return fmt.Errorf("config: default of %s: %w", field.Name, err)
+		}
+		return nil
+	})
+}
+
+func (l *configLoader) loadFile(config interface{}) error {
+	data, err := os.ReadFile(l.file)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(l.file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, config)
+	case ".json":
+		err = json.Unmarshal(data, config)
+	case ".toml":
+		err = toml.Unmarshal(data, config)
+	default:
+		return fmt.Errorf("config: unknown file format %s", l.file)
+	}
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", l.file, err)
+	}
+	return nil
+}
+
+func (l *configLoader) loadEnv(value reflect.Value, prefix string) error {
+	return walkConfig(value, prefix, func(field reflect.StructField, v func() reflect.Value, name string) error {
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			return nil
+		}
+		err := setFromString(v(), env)
+		if err != nil {
+			return fmt.Errorf("config: env %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+func (l *configLoader) loadFlags(value reflect.Value) error {
+	flagSet := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	fields := make(map[string]func() reflect.Value)
+	err := walkConfig(value, "", func(field reflect.StructField, v func() reflect.Value, _ string) error {
+		name := field.Tag.Get(tag_flag)
+		if name == "" {
+			return nil
+		}
+		fields[name] = v
+		t := field.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Bool {
+			flagSet.Bool(name, false, field.Name) // so that -debug alone sets it
+		} else {
+			flagSet.String(name, "", field.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = flagSet.Parse(l.args)
+	if err != nil {
+		return err
+	}
+
+	flagSet.Visit(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		setErr := setFromString(fields[f.Name](), f.Value.String())
+		if setErr != nil {
+			err = fmt.Errorf("config: flag %s: %w", f.Name, setErr)
+		}
+	})
+	return err
+}
+
+// walkConfig calls fn on every leaf field with its environment variable name. The value of a
+// field is got with v, which allocates the nil pointer sections holding it, so that a section
+// set by no source stays nil.
+func walkConfig(value reflect.Value, prefix string, fn func(field reflect.StructField, v func() reflect.Value, env string) error) error {
+	return walkSection(func() reflect.Value { return value }, value.Type(), prefix, fn)
+}
+
+func walkSection(section func() reflect.Value, t reflect.Type, prefix string, fn func(field reflect.StructField, v func() reflect.Value, env string) error) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		index := i
+		v := func() reflect.Value {
+			return section().Field(index)
+		}
+
+		name := field.Tag.Get(tag_env)
+		if name == "" {
+			name = strcase.ToScreamingSnake(field.Name)
+		}
+
+		if isConfigStruct(field.Type) {
+			child, childType := v, field.Type
+			if field.Type.Kind() == reflect.Ptr {
+				childType = field.Type.Elem()
+				child = func() reflect.Value {
+					ptr := v()
+					if ptr.IsNil() {
+						ptr.Set(reflect.New(childType))
+					}
+					return ptr.Elem()
+				}
+			}
+			childPrefix := prefix
+			if !field.Anonymous {
+				childPrefix += name + "_"
+			}
+			err := walkSection(child, childType, childPrefix, fn)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := fn(field, v, prefix+name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isConfigStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{})
+}
+
+func setFromString(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		items := make([]string, 0)
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			err := setFromString(slice.Index(i), item)
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Duration is a time.Duration read from strings such as "30s" in every config format,
+// a JSON number being read as nanoseconds
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var n int64
+	if json.Unmarshal(data, &n) == nil {
+		*d = Duration(n)
+		return nil
+	}
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return fmt.Errorf("duration must be a string or a number of nanoseconds: %s", data)
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// ServerConfig holds the engine settings that are usually deployment specific.
+// Embed it in the config struct given to LoadConfig and pass Options to NewEngine.
+type ServerConfig struct {
+	Addr            string      `json:"addr" yaml:"addr" toml:"addr" flag:"addr" default:":8080"`
+	Mode            string      `json:"mode" yaml:"mode" toml:"mode" env:"GIN_MODE" flag:"mode" default:"debug" binding:"oneof=release debug test"`
+	TrustedProxies  []string    `json:"trusted_proxies" yaml:"trusted_proxies" toml:"trusted_proxies"`
+	TrustedPlatform string      `json:"trusted_platform" yaml:"trusted_platform" toml:"trusted_platform"`
+	ShutdownTimeout Duration    `json:"shutdown_timeout" yaml:"shutdown_timeout" toml:"shutdown_timeout" default:"30s"`
+	DisabledModules []string    `json:"disabled_modules" yaml:"disabled_modules" toml:"disabled_modules"`
+	CORS            *CORSConfig `json:"cors" yaml:"cors" toml:"cors"`
+}
+
+type CORSConfig struct {
+	AllowOrigins     []string `json:"allow_origins" yaml:"allow_origins" toml:"allow_origins"`
+	AllowMethods     []string `json:"allow_methods" yaml:"allow_methods" toml:"allow_methods"`
+	AllowHeaders     []string `json:"allow_headers" yaml:"allow_headers" toml:"allow_headers"`
+	ExposeHeaders    []string `json:"expose_headers" yaml:"expose_headers" toml:"expose_headers"`
+	AllowCredentials bool     `json:"allow_credentials" yaml:"allow_credentials" toml:"allow_credentials"`
+	MaxAge           Duration `json:"max_age" yaml:"max_age" toml:"max_age"`
+}
+
+// Options converts the config to NewEngine options
+func (c *ServerConfig) Options() []Option {
+	opts := []Option{
+		WithMode(c.Mode),
+		WithShutdownTimeout(time.Duration(c.ShutdownTimeout)),
+		WithDisabledModules(c.DisabledModules...),
+	}
+	if len(c.TrustedProxies) > 0 {
+		opts = append(opts, WithTrustedProxies(c.TrustedProxies...))
+	}
+	if c.TrustedPlatform != "" {
+		opts = append(opts, WithTrustedPlatform(c.TrustedPlatform))
+	}
+	if c.CORS != nil && len(c.CORS.AllowOrigins) > 0 {
+		opts = append(opts, WithCORS(c.CORS.toCORS()))
+	}
+	return opts
+}
+
+func (c *CORSConfig) toCORS() cors.Config {
+	config := cors.DefaultConfig()
+	config.AllowOrigins = c.AllowOrigins
+	config.AllowCredentials = c.AllowCredentials
+	if len(c.AllowMethods) > 0 {
+		config.AllowMethods = c.AllowMethods
+	}
+	if len(c.AllowHeaders) > 0 {
+		config.AllowHeaders = c.AllowHeaders
+	}
+	if len(c.ExposeHeaders) > 0 {
+		config.ExposeHeaders = c.ExposeHeaders
+	}
+	if c.MaxAge > 0 {
+		config.MaxAge = time.Duration(c.MaxAge)
+	}
+	return config
+}
@@ -0,0 +1,190 @@
+package ginger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type testAppConfig struct {
+	ServerConfig `yaml:",inline"`
+	Debug        bool           `json:"debug" yaml:"debug" toml:"debug" flag:"debug"`
+	Workers      int            `json:"workers" yaml:"workers" toml:"workers" flag:"workers" default:"2" binding:"min=1"`
+	Database     testDBConfig   `json:"database" yaml:"database" toml:"database"`
+	Tags         []string       `json:"tags" yaml:"tags" toml:"tags"`
+	Timeout      *time.Duration `json:"timeout" yaml:"timeout" toml:"timeout" default:"5s"`
+}
+
+type testDBConfig struct {
+	DSN string `json:"dsn" yaml:"dsn" toml:"dsn" binding:"required"`
+}
+
+func writeConfig(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	file := writeConfig(t, "config.yaml", `
+addr: ":9000"
+mode: release
+workers: 4
+shutdown_timeout: 10s
+database:
+  dsn: from-file
+cors:
+  allow_origins: [https://example.com]
+  max_age: 12h
+`)
+	t.Setenv("APP_DATABASE_DSN", "from-env")
+	t.Setenv("APP_TAGS", "a, b")
+	t.Setenv("GIN_MODE", "test") // ignored, env tags are prefixed too
+
+	config, err := LoadConfig[testAppConfig](
+		WithConfigFile(file),
+		WithEnvPrefix("APP_"),
+		WithFlags([]string{"-workers", "8", "-debug"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Addr != ":9000" {
+		t.Errorf("addr from the file expected, got %s", config.Addr)
+	}
+	if config.Mode != "release" {
+		t.Errorf("the env tag is prefixed, got mode %s", config.Mode)
+	}
+	if config.Workers != 8 {
+		t.Errorf("workers from the flags expected, got %d", config.Workers)
+	}
+	if !config.Debug {
+		t.Error("a bare -debug flag did not set debug")
+	}
+	if config.Database.DSN != "from-env" {
+		t.Errorf("dsn from the env expected, got %s", config.Database.DSN)
+	}
+	if strings.Join(config.Tags, "|") != "a|b" {
+		t.Errorf("unexpected tags %v", config.Tags)
+	}
+	if time.Duration(config.ShutdownTimeout) != 10*time.Second || time.Duration(config.CORS.MaxAge) != 12*time.Hour {
+		t.Errorf("unexpected durations %v %v", config.ShutdownTimeout, config.CORS.MaxAge)
+	}
+	if config.Timeout == nil || *config.Timeout != 5*time.Second {
+		t.Errorf("timeout from the default expected, got %v", config.Timeout)
+	}
+}
+
+func TestLoadConfigDurations(t *testing.T) {
+	tests := map[string]string{
+		"config.json": `{"shutdown_timeout": "45s", "database": {"dsn": "x"}, "cors": {"max_age": "1h30m"}}`,
+		"config.toml": "shutdown_timeout = \"45s\"\n[database]\ndsn = \"x\"\n[cors]\nmax_age = \"1h30m\"\n",
+		"config.yml":  "shutdown_timeout: 45s\ndatabase:\n  dsn: x\ncors:\n  max_age: 1h30m\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			config, err := LoadConfig[testAppConfig](WithConfigFile(writeConfig(t, name, content)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if time.Duration(config.ShutdownTimeout) != 45*time.Second {
+				t.Errorf("unexpected shutdown timeout %v", time.Duration(config.ShutdownTimeout))
+			}
+			if time.Duration(config.CORS.MaxAge) != 90*time.Minute {
+				t.Errorf("unexpected max age %v", time.Duration(config.CORS.MaxAge))
+			}
+		})
+	}
+
+	// the default applies without a file, and env variables are parsed the same way
+	t.Setenv("SHUTDOWN_TIMEOUT", "2m")
+	t.Setenv("DATABASE_DSN", "x")
+	config, err := LoadConfig[testAppConfig]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Duration(config.ShutdownTimeout) != 2*time.Minute {
+		t.Errorf("unexpected shutdown timeout %v", time.Duration(config.ShutdownTimeout))
+	}
+}
+
+func TestLoadConfigValidates(t *testing.T) {
+	file := writeConfig(t, "config.json", `{"mode": "production"}`)
+	_, err := LoadConfig[testAppConfig](WithConfigFile(file))
+	if err == nil || !strings.Contains(err.Error(), "'oneof'") || !strings.Contains(err.Error(), "'required'") {
+		t.Fatalf("expected oneof and required failures, got %v", err)
+	}
+
+	_, err = LoadConfig[testAppConfig](WithConfigFile(writeConfig(t, "bad.json", `{"shutdown_timeout": "soon"}`)))
+	if err == nil {
+		t.Fatal("an invalid duration was accepted")
+	}
+}
+
+func TestLoadConfigWithValidator(t *testing.T) {
+	type config struct {
+		Region string `json:"region" default:"mars" binding:"region"`
+	}
+	v := NewValidator()
+	err := v.RegisterRule("region", func(ctx context.Context, fl validator.FieldLevel) bool {
+		return fl.Field().String() == "eu" || fl.Field().String() == "us"
+	}, map[string]string{"en": "{0} must be a known region"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadConfig[config](WithConfigValidator(v))
+	if err == nil || !strings.Contains(err.Error(), "'region'") {
+		t.Fatalf("expected the region rule to fail, got %v", err)
+	}
+	t.Setenv("REGION", "eu")
+	if _, err := LoadConfig[config](WithConfigValidator(v)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfigOptionalSections(t *testing.T) {
+	type config struct {
+		Cache *struct {
+			URL string   `json:"url" binding:"required,url"`
+			TTL Duration `json:"ttl"`
+		} `json:"cache"`
+		Mail *struct {
+			Host string `json:"host" default:"localhost"`
+		} `json:"mail"`
+	}
+
+	loaded, err := LoadConfig[config](WithEnvPrefix("OPT_"))
+	if err != nil {
+		t.Fatalf("a missing section was validated: %v", err)
+	}
+	if loaded.Cache != nil {
+		t.Error("a section set by no source was allocated")
+	}
+	if loaded.Mail == nil || loaded.Mail.Host != "localhost" {
+		t.Error("a section with a default was not allocated")
+	}
+
+	t.Setenv("OPT_CACHE_TTL", "1m")
+	_, err = LoadConfig[config](WithEnvPrefix("OPT_"))
+	if err == nil || !strings.Contains(err.Error(), "'required'") {
+		t.Fatalf("a section set by the env was not validated: %v", err)
+	}
+	t.Setenv("OPT_CACHE_URL", "redis://cache:6379")
+	loaded, err = LoadConfig[config](WithEnvPrefix("OPT_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Cache == nil || time.Duration(loaded.Cache.TTL) != time.Minute {
+		t.Fatalf("unexpected cache section %+v", loaded.Cache)
+	}
+}
@@ -1,25 +1,70 @@
 package ginger
 
+import (
+	"embed"
+	"net/http"
+	"time"
+)
+
 const (
 	GIN_MODE_RELEASE = "release"
 	GIN_MODE_DEBUG   = "debug"
 	GIN_MODE_TEST    = "test"
 )
 
+const (
+	DEFAULT_SHUTDOWN_TIMEOUT       = 30 * time.Second
+	DEFAULT_HEALTH_CHECK_TIMEOUT   = 5 * time.Second
+	DEFAULT_HOOK_TIMEOUT           = 30 * time.Second
+	DEFAULT_CERT_RELOAD_INTERVAL   = 30 * time.Second
+	DEFAULT_SSE_HEARTBEAT_INTERVAL = 15 * time.Second
+)
+
+// SSE_EVENT_ERROR is the event carrying the error body of a failed SSE service, in the
+// envelope of the route
+const SSE_EVENT_ERROR = "error"
+
+const (
+	HEALTH_LIVE_ROUTE  = "/livez"
+	HEALTH_READY_ROUTE = "/readyz"
+)
+
 const (
 	ERR_CODE_UNAUTHORIZED          = "96d4227b-2b12-47f0-ade9-e4025b55d9dd"
 	ERR_CODE_FORBIDDEN             = "b126a36b-4e34-4b71-961c-e4bbc14afcd5"
 	ERR_CODE_INTERNAL_SERVER_ERROR = "5d0f92db-572d-4102-940c-69be6719b251"
+	ERR_CODE_SERVICE_UNAVAILABLE   = "6fa26603-1001-4041-bc04-38611109034e"
+	ERR_CODE_VALIDATION            = "4e42f87c-3cc6-4d5e-85a3-e6380ce45b20"
 )
 
 const (
-	tag_uri  = "uri"
-	tag_json = "json"
-	tag_form = "form"
+	context_key_engine = "ginger.engine"
+	context_key_scope  = "ginger.scope"
 )
 
+const (
+	tag_uri    = "uri"
+	tag_json   = "json"
+	tag_form   = "form"
+	tag_header = "header"
+	tag_cookie = "cookie"
+)
+
+// builtinMessages translates the errors above in the locales of the validator messages, keyed
+// by code so that an error registered with one of their names does not take them over
+//
+//go:embed locales/*.json
+var builtinMessages embed.FS
+
 func init() {
-	RegisterError(ERR_CODE_UNAUTHORIZED, "Unauthorized")
-	RegisterError(ERR_CODE_FORBIDDEN, "Forbidden")
-	RegisterError(ERR_CODE_INTERNAL_SERVER_ERROR, "Internal Server Error")
+	RegisterError(ERR_CODE_UNAUTHORIZED, "Unauthorized", WithStatus(http.StatusUnauthorized))
+	RegisterError(ERR_CODE_FORBIDDEN, "Forbidden", WithStatus(http.StatusForbidden))
+	RegisterError(ERR_CODE_INTERNAL_SERVER_ERROR, "Internal Server Error", WithStatus(http.StatusInternalServerError))
+	RegisterError(ERR_CODE_SERVICE_UNAVAILABLE, "Service Unavailable", WithStatus(http.StatusServiceUnavailable), WithRetryable(), WithLogLevel(LOG_LEVEL_WARN))
+	RegisterError(ERR_CODE_VALIDATION, "Validation Failed", WithStatus(http.StatusBadRequest))
+
+	err := defaultErrorCatalog.LoadMessages(builtinMessages, "locales/*.json")
+	if err != nil {
+		panic(err)
+	}
 }
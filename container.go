@@ -0,0 +1,343 @@
+package ginger
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Lifetime int
+
+const (
+	// LIFETIME_SINGLETON providers are constructed once per container
+	LIFETIME_SINGLETON Lifetime = iota
+	// LIFETIME_SCOPED providers are constructed once per request
+	LIFETIME_SCOPED
+)
+
+var (
+	typeOfError      = reflect.TypeOf((*error)(nil)).Elem()
+	typeOfContext    = reflect.TypeOf((*context.Context)(nil)).Elem()
+	typeOfGinContext = reflect.TypeOf((*gin.Context)(nil))
+)
+
+// Container holds the providers used to build the dependencies of handlers and services.
+// A provider is a constructor function whose parameters are its dependencies, returning
+// the provided value and optionally an error. Scoped providers can also depend on the
+// *gin.Context and the context.Context of the request.
+type Container struct {
+	mu           sync.RWMutex
+	providers    map[reflect.Type]*provider
+	requirements []requirement
+}
+
+// requirement is a type that a route resolves, declared with its handler
+type requirement struct {
+	typ reflect.Type
+	by  string
+}
+
+type provider struct {
+	typ         reflect.Type
+	lifetime    Lifetime
+	constructor reflect.Value
+	deps        []reflect.Type
+
+	// the value of a singleton, only kept once constructed so that a failure is retried
+	mu    sync.Mutex
+	built bool
+	value reflect.Value
+}
+
+func NewContainer() *Container {
+	return &Container{
+		providers: make(map[reflect.Type]*provider),
+	}
+}
+
+// Provide registers constructor as the provider of T, replacing any previous provider of T
+func Provide[T any](c *Container, lifetime Lifetime, constructor interface{}) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	fn := reflect.ValueOf(constructor)
+	if fn.Kind() != reflect.Func {
+		panic(fmt.Sprintf("ginger: provider of %s must be a function", typ))
+	}
+	ft := fn.Type()
+	if ft.NumOut() == 0 || ft.NumOut() > 2 || !ft.Out(0).AssignableTo(typ) || (ft.NumOut() == 2 && ft.Out(1) != typeOfError) {
+		panic(fmt.Sprintf("ginger: provider of %s must return (%s) or (%s, error)", typ, typ, typ))
+	}
+
+	deps := make([]reflect.Type, ft.NumIn())
+	for i := range deps {
+		deps[i] = ft.In(i)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.providers[typ] = &provider{
+		typ:         typ,
+		lifetime:    lifetime,
+		constructor: fn,
+		deps:        deps,
+	}
+}
+
+// ProvideValue registers value as the singleton of T
+func ProvideValue[T any](c *Container, value T) {
+	Provide[T](c, LIFETIME_SINGLETON, func() T {
+		return value
+	})
+}
+
+// Dependency returns the type of S, for the Dependencies of a handler
+func Dependency[S any]() reflect.Type {
+	return reflect.TypeOf((*S)(nil)).Elem()
+}
+
+// Resolve returns the S of the request, panicking if it cannot be built. The routes answer
+// the panic with ERR_CODE_INTERNAL_SERVER_ERROR.
+func Resolve[S any, T any](ctx *Context[T]) S {
+	s, err := TryResolve[S](ctx)
+	if err != nil {
+		panic(&resolveError{err: err})
+	}
+	return s
+}
+
+// resolveError is raised by Resolve
+type resolveError struct {
+	err error
+}
+
+// recoverResolve writes the panic of Resolve as an error, see errorOfResolve
+func (ctx *Context[T]) recoverResolve() {
+	if r := recover(); r != nil {
+		ctx.Error(errorOfResolve(r))
+	}
+}
+
+// errorOfResolve turns the panic of Resolve into ERR_CODE_INTERNAL_SERVER_ERROR, other panics
+// being raised again
+func errorOfResolve(r interface{}) Error {
+	resolveErr, ok := r.(*resolveError)
+	if !ok {
+		panic(r)
+	}
+	return WrapError(resolveErr.err, ERR_CODE_INTERNAL_SERVER_ERROR)
+}
+
+// TryResolve returns the S of the request
+func TryResolve[S any, T any](ctx *Context[T]) (S, error) {
+	var s S
+	if ctx.scope == nil {
+		return s, fmt.Errorf("ginger: resolve %T: context has no container", s)
+	}
+	value, err := ctx.scope.resolve(reflect.TypeOf((*S)(nil)).Elem(), nil)
+	if err != nil {
+		return s, err
+	}
+	return value.Interface().(S), nil
+}
+
+// ResolveContext returns the S of the request served with ctx, such as the context given
+// to the rules registered with Validator.RegisterRule
+func ResolveContext[S any](ctx context.Context) (S, error) {
+	var s S
+	scope, ok := ctx.Value(context_key_scope).(*scope)
+	if !ok {
+		return s, fmt.Errorf("ginger: resolve %T: context has no container", s)
+	}
+	value, err := scope.resolve(reflect.TypeOf((*S)(nil)).Elem(), nil)
+	if err != nil {
+		return s, err
+	}
+	return value.Interface().(S), nil
+}
+
+// require makes Validate check that types are provided, by naming what requires them
+func (c *Container) require(by string, types ...reflect.Type) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, typ := range types {
+		c.requirements = append(c.requirements, requirement{typ: typ, by: by})
+	}
+}
+
+// containerSnapshot is the content of a container, see Engine.Install
+type containerSnapshot struct {
+	providers    map[reflect.Type]*provider
+	requirements int
+}
+
+func (c *Container) snapshot() containerSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	providers := make(map[reflect.Type]*provider, len(c.providers))
+	for typ, p := range c.providers {
+		providers[typ] = p
+	}
+	return containerSnapshot{providers: providers, requirements: len(c.requirements)}
+}
+
+func (c *Container) restore(s containerSnapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.providers = s.providers
+	c.requirements = c.requirements[:s.requirements]
+}
+
+// Validate reports missing and cyclic dependencies, singletons depending on scoped providers,
+// and the Dependencies of the handlers that are not provided
+func (c *Container) Validate() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[reflect.Type]int)
+
+	var visit func(p *provider, path []string) error
+	visit = func(p *provider, path []string) error {
+		path = append(path, p.typ.String())
+		switch state[p.typ] {
+		case visiting:
+			return fmt.Errorf("ginger: cyclic dependency %s", strings.Join(path, " -> "))
+		case visited:
+			return nil
+		}
+		state[p.typ] = visiting
+		for _, dep := range p.deps {
+			if p.lifetime == LIFETIME_SCOPED && isRequestType(dep) {
+				continue
+			}
+			depProvider, ok := c.providers[dep]
+			if !ok {
+				return fmt.Errorf("ginger: missing provider of %s required by %s", dep, p.typ)
+			}
+			if p.lifetime == LIFETIME_SINGLETON && depProvider.lifetime == LIFETIME_SCOPED {
+				return fmt.Errorf("ginger: singleton %s depends on scoped %s", p.typ, dep)
+			}
+			err := visit(depProvider, path)
+			if err != nil {
+				return err
+			}
+		}
+		state[p.typ] = visited
+		return nil
+	}
+
+	for _, p := range c.providers {
+		err := visit(p, nil)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range c.requirements {
+		if _, ok := c.providers[r.typ]; !ok && !isRequestType(r.typ) {
+			return fmt.Errorf("ginger: missing provider of %s required by %s", r.typ, r.by)
+		}
+	}
+	return nil
+}
+
+func (c *Container) provider(typ reflect.Type) (*provider, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	p, ok := c.providers[typ]
+	return p, ok
+}
+
+func (c *Container) newScope(ginContext *gin.Context) *scope {
+	return &scope{
+		container:  c,
+		ginContext: ginContext,
+		values:     make(map[reflect.Type]reflect.Value),
+	}
+}
+
+func isRequestType(typ reflect.Type) bool {
+	return typ == typeOfGinContext || typ == typeOfContext
+}
+
+// scope holds the scoped values of a request
+type scope struct {
+	container  *Container
+	ginContext *gin.Context
+
+	mu     sync.Mutex
+	values map[reflect.Type]reflect.Value
+}
+
+// resolve builds typ, path holds the types being built to detect cycles
+func (s *scope) resolve(typ reflect.Type, path []reflect.Type) (reflect.Value, error) {
+	for _, t := range path {
+		if t == typ {
+			return reflect.Value{}, fmt.Errorf("ginger: cyclic dependency on %s", typ)
+		}
+	}
+
+	p, ok := s.container.provider(typ)
+	if !ok {
+		switch {
+		case typ == typeOfGinContext && s.ginContext != nil:
+			return reflect.ValueOf(s.ginContext), nil
+		case typ == typeOfContext && s.ginContext != nil:
+			return reflect.ValueOf(s.ginContext.Request.Context()), nil
+		}
+		return reflect.Value{}, fmt.Errorf("ginger: missing provider of %s", typ)
+	}
+	path = append(path, typ)
+
+	if p.lifetime == LIFETIME_SINGLETON {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if !p.built {
+			value, err := s.construct(p, path)
+			if err != nil {
+				return value, err
+			}
+			p.value, p.built = value, true
+		}
+		return p.value, nil
+	}
+
+	s.mu.Lock()
+	value, ok := s.values[typ]
+	s.mu.Unlock()
+	if ok {
+		return value, nil
+	}
+	value, err := s.construct(p, path)
+	if err != nil {
+		return value, err
+	}
+	s.mu.Lock()
+	s.values[typ] = value
+	s.mu.Unlock()
+	return value, nil
+}
+
+func (s *scope) construct(p *provider, path []reflect.Type) (reflect.Value, error) {
+	args := make([]reflect.Value, len(p.deps))
+	for i, dep := range p.deps {
+		arg, err := s.resolve(dep, path)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		args[i] = arg
+	}
+
+	out := p.constructor.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("ginger: provide %s: %w", p.typ, out[1].Interface().(error))
+	}
+	value := reflect.New(p.typ).Elem()
+	value.Set(out[0])
+	return value, nil
+}
@@ -0,0 +1,207 @@
+package ginger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testConfig struct {
+	name string
+}
+
+type testRepository struct {
+	config *testConfig
+}
+
+type testRequestInfo struct {
+	path string
+}
+
+type testUseCase struct {
+	repository *testRepository
+	request    *testRequestInfo
+}
+
+func newTestContainer() *Container {
+	c := NewContainer()
+	ProvideValue(c, &testConfig{name: "test"})
+	Provide[*testRepository](c, LIFETIME_SINGLETON, func(config *testConfig) *testRepository {
+		return &testRepository{config: config}
+	})
+	Provide[*testRequestInfo](c, LIFETIME_SCOPED, func(c *gin.Context) *testRequestInfo {
+		return &testRequestInfo{path: c.Request.URL.Path}
+	})
+	Provide[*testUseCase](c, LIFETIME_SCOPED, func(r *testRepository, info *testRequestInfo) *testUseCase {
+		return &testUseCase{repository: r, request: info}
+	})
+	return c
+}
+
+func TestContainerResolvesLifetimes(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	engine.Container = newTestContainer()
+
+	var repositories []*testRepository
+	var sameScope []bool
+	GET(engine, "/use-case", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Dependencies: []reflect.Type{Dependency[*testUseCase]()},
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				useCase := Resolve[*testUseCase](ctx)
+				repositories = append(repositories, useCase.repository)
+				sameScope = append(sameScope, Resolve[*testRequestInfo](ctx) == useCase.request)
+				return useCase.request.path, nil
+			},
+		}
+	})
+	if err := engine.Container.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		engine.GinEngine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/use-case", nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"data":"/use-case"`) {
+			t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+		}
+	}
+	if repositories[0] != repositories[1] || repositories[0].config.name != "test" {
+		t.Fatal("the singleton was built twice")
+	}
+	if !sameScope[0] || !sameScope[1] {
+		t.Fatal("the scoped value was built twice in a request")
+	}
+}
+
+func TestContainerValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		provide func(c *Container)
+		err     string
+	}{
+		{
+			name: "missing provider",
+			provide: func(c *Container) {
+				Provide[*testRepository](c, LIFETIME_SINGLETON, func(config *testConfig) *testRepository {
+					return &testRepository{config: config}
+				})
+			},
+			err: "missing provider of *ginger.testConfig required by *ginger.testRepository",
+		},
+		{
+			name: "cycle",
+			provide: func(c *Container) {
+				Provide[*testConfig](c, LIFETIME_SINGLETON, func(*testRepository) *testConfig { return nil })
+				Provide[*testRepository](c, LIFETIME_SINGLETON, func(*testConfig) *testRepository { return nil })
+			},
+			err: "cyclic dependency",
+		},
+		{
+			name: "singleton depending on scoped",
+			provide: func(c *Container) {
+				Provide[*testRequestInfo](c, LIFETIME_SCOPED, func() *testRequestInfo { return nil })
+				Provide[*testUseCase](c, LIFETIME_SINGLETON, func(*testRequestInfo) *testUseCase { return nil })
+			},
+			err: "singleton *ginger.testUseCase depends on scoped *ginger.testRequestInfo",
+		},
+		{
+			name: "handler dependency",
+			provide: func(c *Container) {
+				c.require("GET /users", Dependency[*testRepository](), Dependency[context.Context]())
+			},
+			err: "missing provider of *ginger.testRepository required by GET /users",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewContainer()
+			test.provide(c)
+			err := c.Validate()
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestRunFailsOnMissingHandlerDependency(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	GET(engine, "/users", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Dependencies: []reflect.Type{Dependency[*testRepository]()},
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				return nil, nil
+			},
+		}
+	})
+	err := engine.RunContext(context.Background(), freeAddr(t))
+	if err == nil || !strings.Contains(err.Error(), "required by GET /users") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestResolveFailureIsInternalServerError(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	GET(engine, "/users", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				return Resolve[*testRepository](ctx), nil
+			},
+		}
+	})
+
+	w := httptest.NewRecorder()
+	engine.GinEngine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error == nil || resp.Error.Code != ERR_CODE_INTERNAL_SERVER_ERROR {
+		t.Fatalf("unexpected response %s", w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "testRepository") {
+		t.Fatalf("the cause leaked to the client: %s", w.Body.String())
+	}
+}
+
+func TestSingletonFailureIsRetried(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	calls := 0
+	Provide[*testRepository](engine.Container, LIFETIME_SINGLETON, func() (*testRepository, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("database unreachable")
+		}
+		return &testRepository{}, nil
+	})
+	GET(engine, "/users", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				Resolve[*testRepository](ctx)
+				return nil, nil
+			},
+		}
+	})
+
+	for _, expected := range []int{http.StatusInternalServerError, http.StatusOK, http.StatusOK} {
+		w := httptest.NewRecorder()
+		engine.GinEngine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+		if w.Code != expected {
+			t.Fatalf("expected %d, got %d %s", expected, w.Code, w.Body.String())
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("the singleton was constructed %d times", calls)
+	}
+}
@@ -2,6 +2,7 @@ package ginger
 
 import (
 	"math"
+	"net/http"
 	"net/http/httptest"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +15,10 @@ type Context[T any] struct {
 	Page       *sql.Pagination
 	Sort       *sql.Sort
 	Response   interface{}
+
+	scope    *scope
+	status   int
+	envelope Envelope
 }
 
 type MockContextParams[T any] struct {
@@ -25,6 +30,7 @@ type MockContextParams[T any] struct {
 	ClientIP  string
 	UserAgent string
 	Headers   map[string]string
+	Container *Container
 }
 
 func NewMockContext[T any](param MockContextParams[T]) *Context[T] {
@@ -53,12 +59,16 @@ func NewMockContext[T any](param MockContextParams[T]) *Context[T] {
 		}
 	}
 
-	return &Context[T]{
+	mock := &Context[T]{
 		GinContext: ctx,
 		Request:    param.Request,
 		Page:       param.Page,
 		Sort:       param.Sort,
 	}
+	if param.Container != nil {
+		mock.scope = param.Container.newScope(ctx)
+	}
+	return mock
 }
 
 func (ctx *Context[T]) ClientIP() string {
@@ -82,45 +92,99 @@ func (ctx *Context[T]) OK(data interface{}, page ...*sql.Pagination) {
 			HasNext:   hasNext,
 		}
 	}
-	resp := &Response{
-		Success:    true,
-		Data:       data,
-		Pagination: p,
-	}
+	resp := ctx.envelopeOf().Success(data, p)
 	ctx.Response = resp // for testing
-	ctx.GinContext.JSON(200, resp)
-}
 
-func (ctx *Context[T]) Error(err Error) {
-	resp := &Response{
-		Success: false,
-		Error: &ResponseError{
-			Code:    err.Code(),
-			Message: err.Error(),
-		},
+	status := ctx.status
+	if status == 0 {
+		status = http.StatusOK
 	}
-	ctx.Response = resp // for testing
-	if err.Code() == ERR_CODE_UNAUTHORIZED {
-		ctx.GinContext.JSON(401, resp)
-		return
-	} else if err.Code() == ERR_CODE_FORBIDDEN {
-		ctx.GinContext.JSON(403, resp)
-		return
-	} else if err.Code() == ERR_CODE_INTERNAL_SERVER_ERROR {
-		ctx.GinContext.JSON(500, resp)
+	if isBodiless(status) {
+		ctx.GinContext.Status(status)
 		return
 	}
-	ctx.GinContext.JSON(400, resp)
+	ctx.GinContext.JSON(status, resp)
+}
+
+// isBodiless reports whether responses with status have no body, which the generated client
+// expects too
+func isBodiless(status int) bool {
+	return status == http.StatusNoContent || status == http.StatusResetContent || status == http.StatusNotModified
+}
+
+// Status sets the status written by OK, 200 by default. Errors keep their own status.
+func (ctx *Context[T]) Status(status int) {
+	ctx.status = status
+}
+
+// Created makes OK answer 201 with the Location of the new resource
+func (ctx *Context[T]) Created(location string) {
+	ctx.Status(http.StatusCreated)
+	ctx.Header("Location", location)
+}
+
+// NoContent makes OK answer 204 without a body
+func (ctx *Context[T]) NoContent() {
+	ctx.Status(http.StatusNoContent)
+}
+
+// Header sets a response header, or deletes it when value is empty
+func (ctx *Context[T]) Header(key string, value string) {
+	ctx.GinContext.Header(key, value)
+}
+
+// SetCookie adds a Set-Cookie header to the response
+func (ctx *Context[T]) SetCookie(cookie *http.Cookie) {
+	http.SetCookie(ctx.GinContext.Writer, cookie)
+}
+
+// Error writes err with the status registered for its code, 400 when it has none
+func (ctx *Context[T]) Error(err Error) {
+	ctx.writeError(ctx.errorInfoOf(err), err)
 }
 
 func (ctx *Context[T]) ErrorWithStatus(status int, err Error) {
-	resp := &Response{
-		Success: false,
-		Error: &ResponseError{
-			Code:    err.Code(),
-			Message: err.Error(),
-		},
+	info := ctx.errorInfoOf(err)
+	info.Status = status
+	ctx.writeError(info, err)
+}
+
+func (ctx *Context[T]) writeError(info ErrorInfo, err Error) {
+	ctx.Response = writeError(ctx.GinContext, engineOf(ctx.GinContext), ctx.envelopeOf(), info, err) // for testing
+}
+
+// errorBodyOf returns the body of the error response for err in envelope, its message being
+// localized for the request
+func errorBodyOf(c *gin.Context, engine *Engine, envelope Envelope, info ErrorInfo, err Error) interface{} {
+	return envelope.Error(c, info.Status, &ResponseError{
+		Code:      err.Code(),
+		Message:   messageOf(errorCatalogOf(engine).Message(info, requestLocales(c)...), err),
+		Fields:    fieldsOf(err),
+		Retryable: info.Retryable,
+		Details:   detailsOf(err),
+	})
+}
+
+// writeError logs err and writes its response with the status of info
+func writeError(c *gin.Context, engine *Engine, envelope Envelope, info ErrorInfo, err Error) interface{} {
+	body := errorBodyOf(c, engine, envelope, info, err)
+	logError(c.Request, info, err)
+	c.Header("Content-Type", envelope.ErrorContentType())
+	c.JSON(info.Status, body)
+	return body
+}
+
+func (ctx *Context[T]) envelopeOf() Envelope {
+	if ctx.envelope != nil {
+		return ctx.envelope
 	}
-	ctx.Response = resp // for testing
-	ctx.GinContext.JSON(status, resp)
+	return envelopeOf(engineOf(ctx.GinContext))
+}
+
+func (ctx *Context[T]) errorInfoOf(err Error) ErrorInfo {
+	return ctx.errorCatalog().Lookup(err.Code())
+}
+
+func (ctx *Context[T]) errorCatalog() *ErrorCatalog {
+	return errorCatalogOf(engineOf(ctx.GinContext))
 }
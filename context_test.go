@@ -0,0 +1,45 @@
+package ginger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOKWithoutBody(t *testing.T) {
+	for _, status := range []int{http.StatusNoContent, http.StatusResetContent, http.StatusNotModified} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			engine := NewEngine(WithMode(GIN_MODE_TEST))
+			POST(engine, "/reset", func() HandlerResponse[struct{}] {
+				return HandlerResponse[struct{}]{
+					Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+						ctx.Status(status)
+						return "ignored", nil
+					},
+				}
+			})
+			w := httptest.NewRecorder()
+			engine.GinEngine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/reset", nil))
+			if w.Code != status || w.Body.Len() != 0 {
+				t.Fatalf("expected %d without a body, got %d %s", status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestOKWithBody(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	POST(engine, "/users", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				ctx.Created("/users/1")
+				return "ann", nil
+			},
+		}
+	})
+	w := httptest.NewRecorder()
+	engine.GinEngine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", nil))
+	if w.Code != http.StatusCreated || w.Body.String() != `{"success":true,"data":"ann"}` {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+}
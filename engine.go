@@ -1,10 +1,15 @@
 package ginger
 
 import (
-	"net/http"
+	"context"
 	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ginger-go/ginger/openapi"
 	"github.com/ginger-go/ginger/typescript"
 	"github.com/ginger-go/sql"
 	"github.com/gorilla/websocket"
@@ -16,28 +21,86 @@ type Engine struct {
 	ModelConverter *typescript.ModelConverter
 	ApiConverter   *typescript.ApiConverter
 	CronWorker     *cron.Cron
+	Container      *Container
+	Validator      *Validator
+	Errors         *ErrorCatalog
+
+	// OpenAPIInfo is the info object of the generated OpenAPI document
+	OpenAPIInfo openapi.Info
+
+	// ShutdownTimeout bounds how long Run waits for in-flight requests and cron jobs,
+	// DEFAULT_SHUTDOWN_TIMEOUT when zero or negative
+	ShutdownTimeout time.Duration
+
+	// ReadinessDrain is how long Run keeps serving requests after readiness starts failing,
+	// so that load balancers stop routing to the engine before its servers shut down
+	ReadinessDrain time.Duration
+
+	// HookTimeout bounds each OnStart and OnReady hook
+	HookTimeout time.Duration
+
+	// HealthCheckTimeout bounds each readiness check, DEFAULT_HEALTH_CHECK_TIMEOUT when zero
+	// or negative
+	HealthCheckTimeout time.Duration
+
+	config        *engineConfig
+	listeners     map[string]*Listener
+	listenerNames []string
+	cronJobs      jobTracker
+	cronRunning   atomic.Bool
+	shuttingDown  atomic.Bool
+	wsUpgrader    websocket.Upgrader
+	routes        []RouteInfo
+	healthMu      sync.RWMutex
+	healthChecks  []namedHealthCheck
+
+	modules         []string
+	currentModule   string
+	disabledModules map[string]bool
+	onStart         []hook
+	onReady         []hook
+	onShutdown      []hook
+	installing      *installation
 }
 
-func NewEngine() *Engine {
-	return &Engine{
-		GinEngine:      gin.Default(),
-		ModelConverter: typescript.NewModelConverter(),
-		ApiConverter:   typescript.NewApiConverter(),
-		CronWorker:     cron.New(),
+func NewEngine(opts ...Option) *Engine {
+	config := newEngineConfig(opts...)
+	engine := &Engine{
+		GinEngine:          config.newGinEngine(),
+		ModelConverter:     typescript.NewModelConverter(),
+		ApiConverter:       typescript.NewApiConverter(),
+		CronWorker:         cron.New(),
+		Container:          NewContainer(),
+		Validator:          NewValidator(),
+		Errors:             NewErrorCatalog(defaultErrorCatalog),
+		OpenAPIInfo:        openapi.Info{Title: "ginger", Version: "1.0.0"},
+		ShutdownTimeout:    config.shutdownTimeout,
+		ReadinessDrain:     config.readinessDrain,
+		HealthCheckTimeout: DEFAULT_HEALTH_CHECK_TIMEOUT,
+		HookTimeout:        DEFAULT_HOOK_TIMEOUT,
+		wsUpgrader:         config.wsUpgrader,
+		config:             config,
+		listeners:          make(map[string]*Listener),
+		disabledModules:    make(map[string]bool),
 	}
+	engine.DisableModules(config.disabledModules...)
+	return engine
 }
 
-func (e *Engine) Run(addr string) {
-	e.CronWorker.Start()
-	e.GinEngine.Run(addr)
+// Run starts the cron worker and the http server, and blocks until SIGINT or SIGTERM
+func (e *Engine) Run(addr string) error {
+	return e.RunContext(context.Background(), addr)
 }
 
-func (e *Engine) RunServerOnly(addr string) {
-	e.GinEngine.Run(addr)
+// RunServerOnly starts the http server without the cron worker
+func (e *Engine) RunServerOnly(addr string) error {
+	return e.run(context.Background(), addr, true, false, nil)
 }
 
+// RunCronOnly starts the cron worker and returns at once, without running the lifecycle hooks
+// nor stopping the worker on shutdown. Use RunCronContext for a managed cron only process.
 func (e *Engine) RunCronOnly() {
-	e.CronWorker.Start()
+	e.startCron()
 }
 
 func (e *Engine) Use(middleware ...gin.HandlerFunc) {
@@ -45,6 +108,16 @@ func (e *Engine) Use(middleware ...gin.HandlerFunc) {
 }
 
 func (e *Engine) GenerateTypescript(folderPath string) {
+	errorConverter := typescript.NewErrorConverter()
+	for _, info := range e.Errors.All() {
+		errorConverter.Add(info.Namespace, info.Name, info.Code)
+		if info.Details != nil {
+			details := reflect.New(info.Details).Interface()
+			e.ModelConverter.Add(details)
+			e.ApiConverter.AddErrorDetails(info.Code, details)
+		}
+	}
+
 	os.RemoveAll(folderPath)
 	err := os.Mkdir(folderPath, os.ModePerm)
 	if err != nil {
@@ -60,90 +133,181 @@ func (e *Engine) GenerateTypescript(folderPath string) {
 	if err != nil {
 		panic(err)
 	}
+
+	err = os.WriteFile(folderPath+"/errors.ts", []byte(errorConverter.ToString()), os.ModePerm)
+	if err != nil {
+		panic(err)
+	}
 }
 
-func GET[T any](engine *Engine, route string, handler Handler[T], middleware ...gin.HandlerFunc) {
-	setup := handler()
-	engine.ModelConverter.Add(new(T))
-	engine.ModelConverter.Add(setup.Response)
-	engine.ApiConverter.Add("GET", route, new(T), setup.Response, handler, setup.Pagination, setup.Sort)
-	engine.GinEngine.GET(route, joinMiddlewareAndService(newGinServiceHandler(handler), middleware...)...)
+func GET[T any](router Router, route string, handler Handler[T], middleware ...gin.HandlerFunc) {
+	handle(router, "GET", route, handler, middleware...)
 }
 
-func POST[T any](engine *Engine, route string, handler Handler[T], middleware ...gin.HandlerFunc) {
-	setup := handler()
-	engine.ModelConverter.Add(new(T))
-	engine.ModelConverter.Add(setup.Response)
-	engine.ApiConverter.Add("POST", route, new(T), setup.Response, handler, setup.Pagination, setup.Sort)
-	engine.GinEngine.POST(route, joinMiddlewareAndService(newGinServiceHandler(handler), middleware...)...)
+func POST[T any](router Router, route string, handler Handler[T], middleware ...gin.HandlerFunc) {
+	handle(router, "POST", route, handler, middleware...)
+}
+
+func PUT[T any](router Router, route string, handler Handler[T], middleware ...gin.HandlerFunc) {
+	handle(router, "PUT", route, handler, middleware...)
+}
+
+func DELETE[T any](router Router, route string, handler Handler[T], middleware ...gin.HandlerFunc) {
+	handle(router, "DELETE", route, handler, middleware...)
+}
+
+func PATCH[T any](router Router, route string, handler Handler[T], middleware ...gin.HandlerFunc) {
+	handle(router, "PATCH", route, handler, middleware...)
+}
+
+func HEAD[T any](router Router, route string, handler Handler[T], middleware ...gin.HandlerFunc) {
+	handle(router, "HEAD", route, handler, middleware...)
 }
 
-func PUT[T any](engine *Engine, route string, handler Handler[T], middleware ...gin.HandlerFunc) {
+func OPTIONS[T any](router Router, route string, handler Handler[T], middleware ...gin.HandlerFunc) {
+	handle(router, "OPTIONS", route, handler, middleware...)
+}
+
+func handle[T any](router Router, method string, route string, handler Handler[T], middleware ...gin.HandlerFunc) {
+	group := router.group()
+	engine := group.engine
 	setup := handler()
-	engine.ModelConverter.Add(new(T))
-	engine.ModelConverter.Add(setup.Response)
-	engine.ApiConverter.Add("PUT", route, new(T), setup.Response, handler, setup.Pagination, setup.Sort)
-	engine.GinEngine.PUT(route, joinMiddlewareAndService(newGinServiceHandler(handler), middleware...)...)
+	envelope := group.envelopeOf()
+	fileFieldsOf(typeOfModel(new(T))) // panics on a malformed file tag
+	if setup.Raw {
+		engine.addRoute(RouteInfo{
+			Listener:    group.listener,
+			Method:      method,
+			Path:        group.fullRoute(route),
+			Kind:        ROUTE_KIND_RAW,
+			Request:     typeOfModel(new(T)),
+			HandlerName: nameOfHandler(handler),
+			Envelope:    envelope,
+		})
+		engine.apply(func() {
+			engine.ModelConverter.Add(new(T))
+			engine.ApiConverter.AddRaw(method, group.fullRoute(route), new(T), handler)
+		})
+	} else {
+		engine.addRoute(RouteInfo{
+			Listener:    group.listener,
+			Method:      method,
+			Path:        group.fullRoute(route),
+			Kind:        ROUTE_KIND_JSON,
+			Request:     typeOfModel(new(T)),
+			Response:    typeOfModel(setup.Response),
+			HandlerName: nameOfHandler(handler),
+			Pagination:  setup.Pagination,
+			Sort:        setup.Sort,
+			Status:      setup.Status,
+			Envelope:    envelope,
+		})
+		engine.apply(func() {
+			engine.ModelConverter.Add(new(T))
+			engine.ModelConverter.Add(setup.Response)
+			engine.ApiConverter.Add(method, group.fullRoute(route), new(T), setup.Response, handler, setup.Pagination, setup.Sort)
+		})
+	}
+	engine.apply(func() {
+		engine.ApiConverter.SetEnvelope(method, group.fullRoute(route), envelope.Name())
+	})
+	engine.Container.require(method+" "+group.fullRoute(route), setup.Dependencies...)
+	engine.ginHandle(group, method, route, joinMiddlewareAndService(newGinServiceHandler(engine, envelope, handler), middleware...)...)
 }
 
-func DELETE[T any](engine *Engine, route string, handler Handler[T], middleware ...gin.HandlerFunc) {
+func WS[T any](router Router, route string, handler WSHandler[T], middleware ...gin.HandlerFunc) {
+	group := router.group()
 	setup := handler()
-	engine.ModelConverter.Add(new(T))
-	engine.ModelConverter.Add(setup.Response)
-	engine.ApiConverter.Add("DELETE", route, new(T), setup.Response, handler, setup.Pagination, setup.Sort)
-	engine.GinEngine.DELETE(route, joinMiddlewareAndService(newGinServiceHandler(handler), middleware...)...)
+	fileFieldsOf(typeOfModel(new(T))) // panics on a malformed file tag
+	group.engine.addRoute(RouteInfo{
+		Listener:    group.listener,
+		Method:      "GET",
+		Path:        group.fullRoute(route),
+		Kind:        ROUTE_KIND_WEBSOCKET,
+		Request:     typeOfModel(new(T)),
+		HandlerName: nameOfHandler(handler),
+	})
+	group.engine.Container.require("GET "+group.fullRoute(route), setup.Dependencies...)
+	group.engine.ginHandle(group, "GET", route, joinMiddlewareAndService(newGinWSServiceHandler(group.engine, group.envelopeOf(), handler), middleware...)...)
 }
 
-func WS[T any](engine *Engine, route string, handler WSHandler[T], middleware ...gin.HandlerFunc) {
-	engine.GinEngine.GET(route, joinMiddlewareAndService(newGinWSServiceHandler(handler), middleware...)...)
+// SSE registers a GET route streaming server-sent events. The request is bound before the
+// stream starts, so binding errors are written as a JSON Response.
+func SSE[T any](router Router, route string, handler SSEHandler[T], middleware ...gin.HandlerFunc) {
+	group := router.group()
+	engine := group.engine
+	setup := handler()
+	fileFieldsOf(typeOfModel(new(T))) // panics on a malformed file tag
+	engine.addRoute(RouteInfo{
+		Listener:    group.listener,
+		Method:      "GET",
+		Path:        group.fullRoute(route),
+		Kind:        ROUTE_KIND_SSE,
+		Request:     typeOfModel(new(T)),
+		Response:    typeOfModel(setup.Event),
+		HandlerName: nameOfHandler(handler),
+	})
+	engine.apply(func() {
+		engine.ModelConverter.Add(new(T))
+		engine.ModelConverter.Add(setup.Event)
+		engine.ApiConverter.AddSSE(group.fullRoute(route), new(T), setup.Event, handler)
+	})
+	engine.Container.require("GET "+group.fullRoute(route), setup.Dependencies...)
+	engine.ginHandle(group, "GET", route, joinMiddlewareAndService(newGinSSEServiceHandler(engine, group.envelopeOf(), handler), middleware...)...)
 }
 
 func Cron(engine *Engine, spec string, job func()) {
-	engine.CronWorker.AddFunc(spec, job)
+	engine.apply(func() {
+		engine.CronWorker.AddFunc(spec, engine.cronJobs.wrap(job))
+	})
 }
 
-func newGinServiceHandler[T any](handler Handler[T]) gin.HandlerFunc {
+func newGinServiceHandler[T any](engine *Engine, envelope Envelope, handler Handler[T]) gin.HandlerFunc {
 	handlerSetup := handler()
 	return func(c *gin.Context) {
-		ctx := &Context[T]{
-			GinContext: c,
-			Request:    GinRequest[T](c),
+		ctx := newContext[T](engine, envelope, c)
+		defer ctx.recoverResolve()
+		ctx.status = handlerSetup.Status
+		var err Error
+		ctx.Request, err = BindRequest[T](c)
+		if err == nil && handlerSetup.Pagination {
+			ctx.Page, err = bindRequest[sql.Pagination](c, tag_form)
 		}
-		if handlerSetup.Pagination {
-			ctx.Page = GinRequest[sql.Pagination](c)
+		if err == nil && handlerSetup.Sort {
+			ctx.Sort, err = bindRequest[sql.Sort](c, tag_form)
 		}
-		if handlerSetup.Sort {
-			ctx.Sort = GinRequest[sql.Sort](c)
+		if err != nil {
+			ctx.Error(err)
+			return
 		}
 		resp, err := handlerSetup.Service(ctx)
 		if err != nil {
-			if err.Code() == ERR_CODE_UNAUTHORIZED {
-				ctx.ErrorWithStatus(401, err)
-				return
-			} else if err.Code() == ERR_CODE_FORBIDDEN {
-				ctx.ErrorWithStatus(403, err)
-				return
-			} else if err.Code() == ERR_CODE_INTERNAL_SERVER_ERROR {
-				ctx.ErrorWithStatus(500, err)
-				return
-			}
 			ctx.Error(err)
 			return
 		}
+		if raw, ok := resp.(*RawResponse); ok {
+			ctx.Response = raw // for testing
+			if err := raw.write(c); err != nil {
+				ctx.Error(WrapError(err, ERR_CODE_INTERNAL_SERVER_ERROR))
+			}
+			return
+		}
 		ctx.OK(resp, ctx.Page)
 	}
 }
 
-var wsUpGrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
-}
-
-func newGinWSServiceHandler[T any](handler WSHandler[T]) gin.HandlerFunc {
+func newGinWSServiceHandler[T any](engine *Engine, envelope Envelope, handler WSHandler[T]) gin.HandlerFunc {
 	handlerSetup := handler()
 	return func(c *gin.Context) {
-		ws, err := wsUpGrader.Upgrade(c.Writer, c.Request, nil)
+		ctx := newContext[T](engine, envelope, c)
+		defer ctx.recoverResolve()
+		var bindErr Error
+		ctx.Request, bindErr = BindRequest[T](c)
+		if bindErr != nil {
+			ctx.Error(bindErr)
+			return
+		}
+		ws, err := engine.wsUpgrader.Upgrade(c.Writer, c.Request, nil)
 		if err != nil {
 			c.JSON(500, gin.H{
 				"error": err.Error(),
@@ -151,17 +315,32 @@ func newGinWSServiceHandler[T any](handler WSHandler[T]) gin.HandlerFunc {
 			return
 		}
 		defer ws.Close()
-		ctx := &Context[T]{
-			GinContext: c,
-			Request:    GinRequest[T](c),
-		}
-		err1 := handlerSetup.Service(ctx, ws)
-		if err != nil {
-			ctx.Error(err1)
+		serviceErr := handlerSetup.Service(ctx, ws)
+		if serviceErr != nil {
+			ctx.Error(serviceErr)
 		}
 	}
 }
 
+// newContext creates the Context of a request and makes the engine and the scope reachable from c
+func newContext[T any](engine *Engine, envelope Envelope, c *gin.Context) *Context[T] {
+	ctx := &Context[T]{
+		GinContext: c,
+		scope:      engine.Container.newScope(c),
+		envelope:   envelope,
+	}
+	c.Set(context_key_engine, engine)
+	c.Set(context_key_scope, ctx.scope)
+	return ctx
+}
+
+func engineOf(c *gin.Context) *Engine {
+	if engine, ok := c.Get(context_key_engine); ok {
+		return engine.(*Engine)
+	}
+	return nil
+}
+
 func joinMiddlewareAndService(service gin.HandlerFunc, middleware ...gin.HandlerFunc) []gin.HandlerFunc {
 	var funcs = make([]gin.HandlerFunc, 0)
 	if len(middleware) > 0 {
@@ -0,0 +1,87 @@
+package ginger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testPatchRequest struct {
+	ID   int    `uri:"id"`
+	Name string `json:"name" binding:"required"`
+}
+
+func TestPatchHeadOptions(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	PATCH(engine, "/users/:id", func() HandlerResponse[testPatchRequest] {
+		return HandlerResponse[testPatchRequest]{
+			Service: func(ctx *Context[testPatchRequest]) (interface{}, Error) {
+				return ctx.Request, nil
+			},
+		}
+	})
+	HEAD(engine, "/users/:id", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				ctx.Header("X-Total", "42")
+				return "ignored", nil
+			},
+		}
+	})
+	OPTIONS(engine, "/users", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				ctx.Header("Allow", "GET, PATCH, HEAD, OPTIONS")
+				return []string{"GET", "PATCH"}, nil
+			},
+		}
+	})
+	server := httptest.NewServer(engine.GinEngine)
+	defer server.Close()
+
+	send := func(method string, path string, body string) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, string(data)
+	}
+
+	resp, body := send(http.MethodPatch, "/users/7", `{"name":"ann"}`)
+	var patched struct {
+		Data testPatchRequest `json:"data"`
+	}
+	json.Unmarshal([]byte(body), &patched)
+	if resp.StatusCode != http.StatusOK || patched.Data != (testPatchRequest{ID: 7, Name: "ann"}) {
+		t.Fatalf("unexpected PATCH response %d %s", resp.StatusCode, body)
+	}
+	if resp, body := send(http.MethodPatch, "/users/7", `{}`); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unexpected PATCH response %d %s", resp.StatusCode, body)
+	}
+
+	resp, body = send(http.MethodHead, "/users/7", "")
+	if resp.StatusCode != http.StatusOK || body != "" || resp.Header.Get("X-Total") != "42" {
+		t.Fatalf("unexpected HEAD response %d %v %q", resp.StatusCode, resp.Header, body)
+	}
+
+	resp, body = send(http.MethodOptions, "/users", "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Allow") == "" || !strings.Contains(body, `"data":["GET","PATCH"]`) {
+		t.Fatalf("unexpected OPTIONS response %d %s", resp.StatusCode, body)
+	}
+
+	methods := make(map[string]bool)
+	for _, route := range engine.Routes() {
+		methods[route.Method] = true
+	}
+	if !methods["PATCH"] || !methods["HEAD"] || !methods["OPTIONS"] {
+		t.Fatalf("unexpected routes %v", engine.Routes())
+	}
+}
@@ -0,0 +1,176 @@
+package ginger
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ENVELOPE_DEFAULT = "default"
+	ENVELOPE_PROBLEM = "problem"
+)
+
+// Envelope shapes the bodies written by Context.OK and Context.Error. It is chosen per engine
+// with WithEnvelope and per group with Group.SetEnvelope.
+type Envelope interface {
+	// Name tells the generated clients how to read the bodies, ENVELOPE_DEFAULT or ENVELOPE_PROBLEM
+	Name() string
+	// Success returns the body of a successful response
+	Success(data interface{}, pagination *PaginationResponse) interface{}
+	// Error returns the body of an error response
+	Error(c *gin.Context, status int, err *ResponseError) interface{}
+	// ErrorContentType is the content type of the error bodies
+	ErrorContentType() string
+	// SuccessModel and ErrorModel are the types of the bodies for the OpenAPI document, the data
+	// property of the success model holding the response of the route
+	SuccessModel() interface{}
+	ErrorModel() interface{}
+}
+
+// DefaultEnvelope writes the Response envelope, for successes and errors alike
+type DefaultEnvelope struct{}
+
+func (DefaultEnvelope) Name() string {
+	return ENVELOPE_DEFAULT
+}
+
+func (DefaultEnvelope) Success(data interface{}, pagination *PaginationResponse) interface{} {
+	return &Response{
+		Success:    true,
+		Data:       data,
+		Pagination: pagination,
+	}
+}
+
+func (DefaultEnvelope) Error(c *gin.Context, status int, err *ResponseError) interface{} {
+	return &Response{
+		Success: false,
+		Error:   err,
+	}
+}
+
+func (DefaultEnvelope) ErrorContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+func (DefaultEnvelope) SuccessModel() interface{} {
+	return Response{}
+}
+
+func (DefaultEnvelope) ErrorModel() interface{} {
+	return Response{}
+}
+
+// Problem is an RFC 9457 problem details object. Code, Fields, Retryable and Details are
+// extension members carrying the ResponseError, Extensions holding any other.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	Retryable bool         `json:"retryable,omitempty"`
+	Details   interface{}  `json:"details,omitempty"`
+
+	Extensions map[string]interface{} `json:"-"`
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	members := make(map[string]interface{})
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	err = json.Unmarshal(data, &members)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// ProblemEnvelope writes errors as application/problem+json, successes keeping the Response envelope
+type ProblemEnvelope struct {
+	// TypeBase prefixes the code of an error to make the problem type, e.g.
+	// https://example.com/errors/, about:blank being used when empty
+	TypeBase string
+	// Extensions adds members to the problem of a request, it may be nil
+	Extensions func(c *gin.Context, err *ResponseError) map[string]interface{}
+}
+
+func (ProblemEnvelope) Name() string {
+	return ENVELOPE_PROBLEM
+}
+
+func (ProblemEnvelope) Success(data interface{}, pagination *PaginationResponse) interface{} {
+	return DefaultEnvelope{}.Success(data, pagination)
+}
+
+func (e ProblemEnvelope) Error(c *gin.Context, status int, err *ResponseError) interface{} {
+	problem := &Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    err.Message,
+		Instance:  c.Request.URL.RequestURI(),
+		Code:      err.Code,
+		Fields:    err.Fields,
+		Retryable: err.Retryable,
+		Details:   err.Details,
+	}
+	if e.TypeBase != "" {
+		problem.Type = e.TypeBase + err.Code
+	}
+	if e.Extensions != nil {
+		problem.Extensions = e.Extensions(c, err)
+	}
+	return problem
+}
+
+func (ProblemEnvelope) ErrorContentType() string {
+	return "application/problem+json"
+}
+
+func (ProblemEnvelope) SuccessModel() interface{} {
+	return Response{}
+}
+
+func (ProblemEnvelope) ErrorModel() interface{} {
+	return Problem{}
+}
+
+// WithEnvelope sets the envelope of the engine, DefaultEnvelope when not given
+func WithEnvelope(envelope Envelope) Option {
+	return func(c *engineConfig) {
+		c.envelope = envelope
+	}
+}
+
+// SetEnvelope sets the envelope of the routes registered on g and its new subgroups from now on
+func (g *Group) SetEnvelope(envelope Envelope) {
+	g.envelope = envelope
+}
+
+// envelopeOf returns the envelope of g, falling back to the one of the engine
+func (g *Group) envelopeOf() Envelope {
+	if g.envelope != nil {
+		return g.envelope
+	}
+	return envelopeOf(g.engine)
+}
+
+// envelopeOf returns the envelope of engine, or DefaultEnvelope when engine is nil
+func envelopeOf(engine *Engine) Envelope {
+	if engine != nil && engine.config.envelope != nil {
+		return engine.config.envelope
+	}
+	return DefaultEnvelope{}
+}
@@ -1,26 +1,170 @@
 package ginger
 
-var errMap = make(map[interface{}]string)
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+)
 
 type Error interface {
 	Code() string
 	Error() string
 }
 
-func NewError(code string) Error {
+// LogLevel is the level at which an error is logged when it is written to a client
+type LogLevel int
+
+const (
+	LOG_LEVEL_NONE LogLevel = iota
+	LOG_LEVEL_DEBUG
+	LOG_LEVEL_INFO
+	LOG_LEVEL_WARN
+	LOG_LEVEL_ERROR
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LOG_LEVEL_DEBUG:
+		return "DEBUG"
+	case LOG_LEVEL_INFO:
+		return "INFO"
+	case LOG_LEVEL_WARN:
+		return "WARNING"
+	case LOG_LEVEL_ERROR:
+		return "ERROR"
+	}
+	return "NONE"
+}
+
+// NewError returns the error of code, params formatting its registered message with fmt.Sprintf.
+// The message is the one of the shared catalog, the one of a code registered in an engine catalog
+// being resolved when the error is written.
+func NewError(code string, params ...interface{}) Error {
 	return &errorImp{
 		code:    code,
-		message: errMap[code],
+		message: formatMessage(defaultErrorCatalog.Lookup(code).Message, params),
+		params:  params,
+	}
+}
+
+func formatMessage(message string, params []interface{}) string {
+	if len(params) == 0 || message == "" {
+		return message
+	}
+	return fmt.Sprintf(message, params...)
+}
+
+// messageOf returns the message of err, formatted from template when err was built by NewError
+// with the same code
+func messageOf(template string, err Error) string {
+	var e *errorImp
+	if errors.As(err, &e) && e.code == err.Code() && template != "" {
+		return formatMessage(template, e.params)
 	}
+	return err.Error()
+}
+
+// WrapError returns the error of code caused by cause. The cause is logged when the error is
+// written, but never sent to the client.
+func WrapError(cause error, code string, params ...interface{}) Error {
+	err := NewError(code, params...).(*errorImp)
+	err.cause = cause
+	return err
+}
+
+// ErrorWithDetails returns err carrying details, a map or a struct sent to the client
+// in the details of the ResponseError
+func ErrorWithDetails(err Error, details interface{}) Error {
+	if e, ok := err.(*errorImp); ok {
+		withDetails := *e
+		withDetails.details = details
+		return &withDetails
+	}
+	return &errorImp{
+		code:    err.Code(),
+		message: err.Error(),
+		inner:   err,
+		details: details,
+		fields:  fieldsOf(err),
+	}
+}
+
+// DetailsOf returns the details of err when they are a D
+func DetailsOf[D any](err error) (D, bool) {
+	var withDetails interface{ Details() interface{} }
+	if errors.As(err, &withDetails) {
+		if details, ok := withDetails.Details().(D); ok {
+			return details, true
+		}
+	}
+	var zero D
+	return zero, false
+}
+
+// IsCode reports whether err or one of the errors it wraps is an Error of code
+func IsCode(err error, code string) bool {
+	return errors.Is(err, &errorImp{code: code})
 }
 
-func RegisterError(uuid string, message string) {
-	errMap[uuid] = message
+// RegisterError registers the message of a code, and its status and metadata, in the catalog
+// shared by every engine, e.g.
+//
+//	RegisterError(ERR_CODE_USER_NOT_FOUND, "User Not Found", WithStatus(http.StatusNotFound))
+//
+// It is meant for init functions, a duplicate code being reported when an engine starts.
+func RegisterError(uuid string, message string, opts ...ErrorOption) {
+	defaultErrorCatalog.Register(uuid, message, opts...)
+}
+
+// ErrorInfoOf returns what the shared catalog knows about code, an unregistered code answering 400
+func ErrorInfoOf(code string) ErrorInfo {
+	return defaultErrorCatalog.Lookup(code)
+}
+
+// logError logs err written to the client of request at the level registered for its code,
+// an error with a cause being logged at LOG_LEVEL_ERROR when its code has no level
+func logError(request *http.Request, info ErrorInfo, err Error) {
+	cause := causeOf(err)
+	level := info.LogLevel
+	if level == LOG_LEVEL_NONE && cause != nil {
+		level = LOG_LEVEL_ERROR
+	}
+	if level == LOG_LEVEL_NONE {
+		return
+	}
+	if cause != nil {
+		log.Printf("[%s] %s %s: %s (%s): %v", level, request.Method, request.URL.Path, messageOf(info.Message, err), err.Code(), cause)
+		return
+	}
+	log.Printf("[%s] %s %s: %s (%s)", level, request.Method, request.URL.Path, messageOf(info.Message, err), err.Code())
+}
+
+// causeOf returns the cause given to WrapError, if any
+func causeOf(err Error) error {
+	var e *errorImp
+	if errors.As(err, &e) {
+		return e.cause
+	}
+	return nil
+}
+
+// detailsOf returns the details carried by err, if any
+func detailsOf(err Error) interface{} {
+	if v, ok := err.(interface{ Details() interface{} }); ok {
+		return v.Details()
+	}
+	return nil
 }
 
 type errorImp struct {
 	code    string
 	message string
+	params  []interface{}
+	cause   error
+	inner   Error // the error given to ErrorWithDetails, which is not a cause
+	details interface{}
+	fields  []FieldError
 }
 
 func (e *errorImp) Code() string {
@@ -30,3 +174,24 @@ func (e *errorImp) Code() string {
 func (e *errorImp) Error() string {
 	return e.message
 }
+
+func (e *errorImp) Unwrap() error {
+	if e.cause != nil {
+		return e.cause
+	}
+	return e.inner
+}
+
+func (e *errorImp) Details() interface{} {
+	return e.details
+}
+
+func (e *errorImp) Fields() []FieldError {
+	return e.fields
+}
+
+// Is matches any Error of the same code, so errors.Is(err, NewError(code)) tests the code
+func (e *errorImp) Is(target error) bool {
+	t, ok := target.(Error)
+	return ok && t.Code() == e.code
+}
@@ -0,0 +1,406 @@
+package ginger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/iancoleman/strcase"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrorInfo is what the catalog knows about an error code
+type ErrorInfo struct {
+	Code      string       `json:"code"`
+	Namespace string       `json:"namespace,omitempty"` // the module registering it, unless set with WithNamespace
+	Name      string       `json:"name"`                // the key of the code in the generated clients, from the message unless set with WithName, see Register
+	Message   string       `json:"message"`
+	Status    int          `json:"status"`              // 400 unless set with WithStatus
+	Retryable bool         `json:"retryable,omitempty"` // the client may retry the same request later
+	LogLevel  LogLevel     `json:"-"`                   // LOG_LEVEL_ERROR for 5xx statuses, LOG_LEVEL_NONE otherwise, unless set with WithLogLevel
+	Details   reflect.Type `json:"-"`                   // the type of the details, for the generated clients
+
+	// Messages holds the message by locale, e.g. zh_TW, Message being the fallback
+	Messages map[string]string `json:"messages,omitempty"`
+
+	named bool // Name was set with WithName
+}
+
+type ErrorOption func(*ErrorInfo)
+
+// WithStatus sets the HTTP status written for the error
+func WithStatus(status int) ErrorOption {
+	return func(info *ErrorInfo) {
+		info.Status = status
+	}
+}
+
+// WithRetryable marks the error as temporary, which is told to the client
+func WithRetryable() ErrorOption {
+	return func(info *ErrorInfo) {
+		info.Retryable = true
+	}
+}
+
+// WithLogLevel sets the level at which the error is logged when written
+func WithLogLevel(level LogLevel) ErrorOption {
+	return func(info *ErrorInfo) {
+		info.LogLevel = level
+	}
+}
+
+// WithDetailsType declares the type of the details carried by the error, e.g. WithDetailsType(Conflict{})
+func WithDetailsType(example interface{}) ErrorOption {
+	return func(info *ErrorInfo) {
+		t := reflect.TypeOf(example)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		info.Details = t
+	}
+}
+
+// WithMessages sets the message of the error by locale, e.g. {"zh_TW": "找不到使用者"}
+func WithMessages(messages map[string]string) ErrorOption {
+	return func(info *ErrorInfo) {
+		if info.Messages == nil {
+			info.Messages = make(map[string]string)
+		}
+		for locale, message := range messages {
+			info.Messages[normalizeLocale(locale)] = message
+		}
+	}
+}
+
+// WithNamespace groups the error under namespace in the exported catalog
+func WithNamespace(namespace string) ErrorOption {
+	return func(info *ErrorInfo) {
+		info.Namespace = namespace
+	}
+}
+
+// WithName names the error in the exported catalog, e.g. USER_NOT_FOUND
+func WithName(name string) ErrorOption {
+	return func(info *ErrorInfo) {
+		info.Name = name
+		info.named = true
+	}
+}
+
+var defaultErrorCatalog = NewErrorCatalog(nil)
+
+// ErrorCatalog holds the registered error codes. It is safe for concurrent use. A code may be
+// registered once across a catalog and its parent, and a name given with WithName once per
+// namespace; duplicates are rejected by Register and reported again by Validate when the
+// engine starts.
+type ErrorCatalog struct {
+	mu           sync.RWMutex
+	parent       *ErrorCatalog
+	errors       map[string]ErrorInfo
+	duplicates   []error
+	translations map[string]map[string]string // locale, then code or [namespace.]name
+}
+
+// NewErrorCatalog returns a catalog falling back to parent, which may be nil
+func NewErrorCatalog(parent *ErrorCatalog) *ErrorCatalog {
+	return &ErrorCatalog{
+		parent:       parent,
+		errors:       make(map[string]ErrorInfo),
+		translations: make(map[string]map[string]string),
+	}
+}
+
+var (
+	formatVerbs  = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+	nonNameChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// Register adds the message of a code, and its status and metadata. A name derived from the
+// message that is already taken in the namespace is suffixed with the code, e.g. two codes
+// registered as "Not Found" are named NOT_FOUND_<code>, so that the names given with WithName
+// only conflict with each other, or with the names of the parent catalog.
+func (c *ErrorCatalog) Register(code string, message string, opts ...ErrorOption) error {
+	info := ErrorInfo{
+		Code:    code,
+		Message: message,
+		Status:  http.StatusBadRequest,
+	}
+	levelSet := false
+	for _, opt := range opts {
+		level := info.LogLevel
+		opt(&info)
+		levelSet = levelSet || info.LogLevel != level
+	}
+	if !levelSet && info.Status >= http.StatusInternalServerError {
+		info.LogLevel = LOG_LEVEL_ERROR
+	}
+	if info.Name == "" {
+		info.Name = strcase.ToScreamingSnake(strings.TrimSpace(nonNameChars.ReplaceAllString(formatVerbs.ReplaceAllString(message, ""), " ")))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, err := c.resolveConflicts(info)
+	if err != nil {
+		c.duplicates = append(c.duplicates, err)
+		return err
+	}
+	c.errors[code] = info
+	return nil
+}
+
+// resolveConflicts checks info against the errors of the catalog and its parents. A duplicate
+// code, or a name given with WithName to two codes of a namespace, is an error. Otherwise a
+// clashing name derived from a message is suffixed with its code: the name of info, or the one
+// of an error of this catalog, the parents never being modified.
+func (c *ErrorCatalog) resolveConflicts(info ErrorInfo) (ErrorInfo, error) {
+	for renamed := true; renamed; {
+		renamed = false
+		for catalog := c; catalog != nil && !renamed; catalog = catalog.parent {
+			if catalog != c {
+				catalog.mu.RLock()
+			}
+			var err error
+			for code, other := range catalog.errors {
+				if other.Code == info.Code {
+					err = fmt.Errorf("error catalog: duplicate code %s, registered as %s and %s", info.Code, other.Message, info.Message)
+				} else if other.Namespace == info.Namespace && other.Name == info.Name {
+					switch {
+					case !info.named:
+						info.Name = nameWithCode(info)
+						renamed = true
+					case !other.named && catalog == c:
+						other.Name = nameWithCode(other)
+						catalog.errors[code] = other
+						renamed = true
+					default:
+						err = fmt.Errorf("error catalog: duplicate name %s in namespace %q, for codes %s and %s", info.Name, info.Namespace, other.Code, info.Code)
+					}
+				}
+				if err != nil || renamed {
+					break
+				}
+			}
+			if catalog != c {
+				catalog.mu.RUnlock()
+			}
+			if err != nil {
+				return info, err
+			}
+		}
+	}
+	return info, nil
+}
+
+// nameWithCode suffixes the name of info with its code, e.g. NOT_FOUND_4E42F87C_3CC6
+func nameWithCode(info ErrorInfo) string {
+	return info.Name + "_" + strcase.ToScreamingSnake(strings.TrimSpace(nonNameChars.ReplaceAllString(info.Code, " ")))
+}
+
+// errorCatalogSnapshot is the content of a catalog, see Engine.Install
+type errorCatalogSnapshot struct {
+	errors       map[string]ErrorInfo
+	duplicates   int
+	translations map[string]map[string]string
+}
+
+func (c *ErrorCatalog) snapshot() errorCatalogSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	s := errorCatalogSnapshot{
+		errors:       make(map[string]ErrorInfo, len(c.errors)),
+		duplicates:   len(c.duplicates),
+		translations: make(map[string]map[string]string, len(c.translations)),
+	}
+	for code, info := range c.errors {
+		s.errors[code] = info
+	}
+	for locale, messages := range c.translations {
+		s.translations[locale] = make(map[string]string, len(messages))
+		for key, message := range messages {
+			s.translations[locale][key] = message
+		}
+	}
+	return s
+}
+
+func (c *ErrorCatalog) restore(s errorCatalogSnapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors = s.errors
+	c.duplicates = c.duplicates[:s.duplicates]
+	c.translations = s.translations
+}
+
+// Lookup returns what is registered for code, an unregistered code answering 400
+func (c *ErrorCatalog) Lookup(code string) ErrorInfo {
+	for catalog := c; catalog != nil; catalog = catalog.parent {
+		catalog.mu.RLock()
+		info, ok := catalog.errors[code]
+		catalog.mu.RUnlock()
+		if ok {
+			return info
+		}
+	}
+	return ErrorInfo{Code: code, Status: http.StatusBadRequest}
+}
+
+// AddMessages adds the messages of a locale, keyed by code, by name for the errors without
+// a namespace, or by namespace.name. They apply to the errors of the parent catalog too.
+func (c *ErrorCatalog) AddMessages(locale string, messages map[string]string) {
+	locale = normalizeLocale(locale)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.translations[locale] == nil {
+		c.translations[locale] = make(map[string]string)
+	}
+	for key, message := range messages {
+		c.translations[locale][key] = message
+	}
+}
+
+// LoadMessages adds the messages of the files of fsys matching pattern, each file being a JSON
+// or YAML object of messages named after its locale, e.g. zh-TW.json or ja.yaml. It is meant
+// for translations embedded with go:embed.
+func (c *ErrorCatalog) LoadMessages(fsys fs.FS, pattern string) error {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		messages := make(map[string]string)
+		ext := path.Ext(file)
+		switch ext {
+		case ".json":
+			err = json.Unmarshal(data, &messages)
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(data, &messages)
+		default:
+			return fmt.Errorf("error catalog: unsupported messages file %s", file)
+		}
+		if err != nil {
+			return fmt.Errorf("error catalog: %s: %w", file, err)
+		}
+		c.AddMessages(strings.TrimSuffix(path.Base(file), ext), messages)
+	}
+	return nil
+}
+
+// defaultLocale is the locale of the messages given to Register
+const defaultLocale = "en"
+
+// Message returns the message template of info in the first of locales having one, falling
+// back to info.Message. info.Message is the en message unless en is translated, so that en-US
+// is preferred to the locales following it.
+func (c *ErrorCatalog) Message(info ErrorInfo, locales ...string) string {
+	keys := []string{info.Code, info.Name}
+	if info.Namespace != "" {
+		keys[1] = info.Namespace + "." + info.Name
+	}
+	for _, locale := range locales {
+		if message, ok := info.Messages[locale]; ok {
+			return message
+		}
+		for catalog := c; catalog != nil; catalog = catalog.parent {
+			catalog.mu.RLock()
+			messages := catalog.translations[locale]
+			for _, key := range keys {
+				if message, ok := messages[key]; ok {
+					catalog.mu.RUnlock()
+					return message
+				}
+			}
+			catalog.mu.RUnlock()
+		}
+		if locale == defaultLocale {
+			return info.Message
+		}
+	}
+	return info.Message
+}
+
+// normalizeLocale turns zh-TW into zh_TW, the form of the locales given by Accept-Language
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(locale, "-", "_")
+}
+
+// All lists the errors of the catalog and its parent, by namespace and name
+func (c *ErrorCatalog) All() []ErrorInfo {
+	all := make([]ErrorInfo, 0)
+	for catalog := c; catalog != nil; catalog = catalog.parent {
+		catalog.mu.RLock()
+		for _, info := range catalog.errors {
+			all = append(all, info)
+		}
+		catalog.mu.RUnlock()
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Namespace != all[j].Namespace {
+			return all[i].Namespace < all[j].Namespace
+		}
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
+
+// Validate reports the duplicates met by Register in the catalog and its parent
+func (c *ErrorCatalog) Validate() error {
+	errs := make([]error, 0)
+	for catalog := c; catalog != nil; catalog = catalog.parent {
+		catalog.mu.RLock()
+		errs = append(errs, catalog.duplicates...)
+		catalog.mu.RUnlock()
+	}
+	return errors.Join(errs...)
+}
+
+// ToJSON exports the catalog as a list of ErrorInfo
+func (c *ErrorCatalog) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(c.All(), "", "  ")
+}
+
+// statuses lists the distinct statuses of the registered errors
+func (c *ErrorCatalog) statuses() []int {
+	seen := make(map[int]bool)
+	statuses := make([]int, 0)
+	for _, info := range c.All() {
+		if !seen[info.Status] {
+			seen[info.Status] = true
+			statuses = append(statuses, info.Status)
+		}
+	}
+	sort.Ints(statuses)
+	return statuses
+}
+
+// RegisterError registers an error in the catalog of the engine, under the namespace of
+// the module being installed unless WithNamespace is given
+func (e *Engine) RegisterError(code string, message string, opts ...ErrorOption) error {
+	if e.currentModule != "" {
+		opts = append([]ErrorOption{WithNamespace(e.currentModule)}, opts...)
+	}
+	return e.Errors.Register(code, message, opts...)
+}
+
+// errorCatalogOf returns the catalog of engine, or the shared one when engine is nil
+func errorCatalogOf(engine *Engine) *ErrorCatalog {
+	if engine != nil && engine.Errors != nil {
+		return engine.Errors
+	}
+	return defaultErrorCatalog
+}
@@ -0,0 +1,162 @@
+package ginger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrorCatalogDerivedNamesDoNotConflict(t *testing.T) {
+	c := NewErrorCatalog(nil)
+	if err := c.Register("code-1", "Not Found"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register("code-2", "Not Found"); err != nil {
+		t.Fatalf("derived names conflict: %v", err)
+	}
+	if name := c.Lookup("code-1").Name; name != "NOT_FOUND" {
+		t.Errorf("the first name was changed to %s", name)
+	}
+	if name := c.Lookup("code-2").Name; name != "NOT_FOUND_CODE_2" {
+		t.Errorf("unexpected name %s", name)
+	}
+
+	// an explicit name takes the derived one over
+	if err := c.Register("code-3", "Missing", WithName("NOT_FOUND")); err != nil {
+		t.Fatal(err)
+	}
+	if name := c.Lookup("code-1").Name; name != "NOT_FOUND_CODE_1" {
+		t.Errorf("the derived name was kept as %s", name)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestErrorCatalogExplicitNamesConflict(t *testing.T) {
+	parent := NewErrorCatalog(nil)
+	c := NewErrorCatalog(parent)
+	parent.Register("code-1", "Not Found", WithName("NOT_FOUND"))
+
+	err := c.Register("code-2", "Missing", WithName("NOT_FOUND"))
+	if err == nil || !strings.Contains(err.Error(), "duplicate name NOT_FOUND") {
+		t.Fatalf("expected a duplicate name, got %v", err)
+	}
+	if err := c.Register("code-3", "Missing", WithName("NOT_FOUND"), WithNamespace("users")); err != nil {
+		t.Fatalf("names of other namespaces conflict: %v", err)
+	}
+	if err := c.Register("code-1", "Other"); err == nil || !strings.Contains(err.Error(), "duplicate code code-1") {
+		t.Fatalf("expected a duplicate code, got %v", err)
+	}
+	if err := c.Validate(); err == nil {
+		t.Fatal("the duplicates are not reported")
+	}
+}
+
+func TestErrorCatalogNeverModifiesItsParent(t *testing.T) {
+	parent := NewErrorCatalog(nil)
+	parent.Register("g-2", "Gone Away")
+	a := NewErrorCatalog(parent)
+	b := NewErrorCatalog(parent)
+
+	err := a.Register("a-2", "x", WithName("GONE_AWAY"))
+	if err == nil || !strings.Contains(err.Error(), "duplicate name GONE_AWAY") {
+		t.Fatalf("expected a duplicate name, got %v", err)
+	}
+	if err := a.Register("a-3", "Gone Away"); err != nil {
+		t.Fatal(err)
+	}
+	if name := a.Lookup("a-3").Name; name != "GONE_AWAY_A_3" {
+		t.Errorf("unexpected name %s", name)
+	}
+	if name := b.Lookup("g-2").Name; name != "GONE_AWAY" {
+		t.Fatalf("the parent was renamed to %s", name)
+	}
+}
+
+func TestBuiltinMessagesAreKeyedByCode(t *testing.T) {
+	c := NewErrorCatalog(nil)
+	c.Register(ERR_CODE_FORBIDDEN, "Forbidden")
+	if err := c.LoadMessages(builtinMessages, "locales/*.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register("u-1", "Denied", WithName("FORBIDDEN")); err != nil {
+		t.Fatal(err)
+	}
+	if message := c.Message(c.Lookup("u-1"), "fr"); message != "Denied" {
+		t.Errorf("the builtin translation moved to the error named FORBIDDEN: %s", message)
+	}
+	if message := c.Message(c.Lookup(ERR_CODE_FORBIDDEN), "fr"); message != "Interdit" {
+		t.Errorf("unexpected message %s", message)
+	}
+}
+
+func TestNewErrorOfEngineCode(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	const code = "8d4bb1e6-4a38-4d43-9d4d-6c1f0f6f6a51"
+	if err := engine.RegisterError(code, "Order %s Not Found", WithStatus(http.StatusNotFound)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the message is resolved by the engine writing the error
+	err := NewError(code, "42")
+
+	GET(engine, "/orders", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				return nil, err
+			},
+		}
+	})
+	w := httptest.NewRecorder()
+	engine.GinEngine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "Order 42 Not Found") {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestErrorCatalogMessagePrefersDefaultLocale(t *testing.T) {
+	c := NewErrorCatalog(nil)
+	c.Register("code-1", "Not Found")
+	c.AddMessages("fr", map[string]string{"code-1": "Introuvable"})
+	info := c.Lookup("code-1")
+
+	tests := map[string]string{
+		"en-US, fr;q=0.5": "Not Found",
+		"en, fr;q=0.5":    "Not Found",
+		"fr, en;q=0.5":    "Introuvable",
+		"de, fr;q=0.5":    "Introuvable",
+		"de":              "Not Found",
+	}
+	for header, expected := range tests {
+		if message := c.Message(info, parseAcceptLanguage(header)...); message != expected {
+			t.Errorf("%s: expected %q, got %q", header, expected, message)
+		}
+	}
+
+	// an en translation still replaces the registered message
+	c.AddMessages("en-US", map[string]string{"code-1": "Not found in the US"})
+	if message := c.Message(info, parseAcceptLanguage("en-US, fr;q=0.5")...); message != "Not found in the US" {
+		t.Errorf("the en-US translation was ignored, got %q", message)
+	}
+}
+
+func TestBuiltinErrorInDefaultLocale(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	GET(engine, "/fail", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				return nil, NewError(ERR_CODE_INTERNAL_SERVER_ERROR)
+			},
+		}
+	})
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set("Accept-Language", "en-US, fr;q=0.5")
+	w := httptest.NewRecorder()
+	engine.GinEngine.ServeHTTP(w, req)
+	expected := ErrorInfoOf(ERR_CODE_INTERNAL_SERVER_ERROR).Message
+	if !strings.Contains(w.Body.String(), `"message":"`+expected+`"`) {
+		t.Fatalf("expected the en message %q, got %s", expected, w.Body.String())
+	}
+}
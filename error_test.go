@@ -0,0 +1,97 @@
+package ginger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testConflict struct {
+	ExistingID int `json:"existing_id"`
+}
+
+type testCustomError struct{}
+
+func (testCustomError) Code() string  { return ERR_CODE_FORBIDDEN }
+func (testCustomError) Error() string { return "custom" }
+
+var errTestDatabase = errors.New("dial tcp 10.0.0.5:5432: connection refused")
+
+func TestWrapError(t *testing.T) {
+	err := WrapError(errTestDatabase, ERR_CODE_SERVICE_UNAVAILABLE)
+	if !errors.Is(err, errTestDatabase) {
+		t.Error("errors.Is does not find the cause")
+	}
+	if !errors.Is(err, NewError(ERR_CODE_SERVICE_UNAVAILABLE)) || errors.Is(err, NewError(ERR_CODE_FORBIDDEN)) {
+		t.Error("errors.Is does not match the code")
+	}
+	wrapped := fmt.Errorf("loading users: %w", err)
+	if !IsCode(wrapped, ERR_CODE_SERVICE_UNAVAILABLE) || IsCode(wrapped, ERR_CODE_FORBIDDEN) {
+		t.Error("IsCode does not match the code through fmt.Errorf")
+	}
+	var e Error
+	if !errors.As(wrapped, &e) || e.Code() != ERR_CODE_SERVICE_UNAVAILABLE {
+		t.Error("errors.As does not find the Error")
+	}
+	if err.Error() != "Service Unavailable" {
+		t.Errorf("the message is not the registered one: %s", err.Error())
+	}
+}
+
+func TestErrorWithDetails(t *testing.T) {
+	err := ErrorWithDetails(WrapError(errTestDatabase, ERR_CODE_FORBIDDEN), testConflict{ExistingID: 7})
+	details, ok := DetailsOf[testConflict](err)
+	if !ok || details.ExistingID != 7 {
+		t.Fatalf("unexpected details %+v", details)
+	}
+	if _, ok := DetailsOf[map[string]interface{}](err); ok {
+		t.Error("details of another type were returned")
+	}
+	if !errors.Is(err, errTestDatabase) {
+		t.Error("the cause was lost")
+	}
+
+	// an Error of another implementation keeps its code and stays reachable
+	custom := ErrorWithDetails(testCustomError{}, map[string]string{"role": "admin"})
+	if custom.Code() != ERR_CODE_FORBIDDEN || custom.Error() != "custom" || !IsCode(custom, ERR_CODE_FORBIDDEN) {
+		t.Fatalf("unexpected error %v", custom)
+	}
+	var inner testCustomError
+	if !errors.As(custom, &inner) {
+		t.Error("errors.As does not find the inner error")
+	}
+	if details, ok := DetailsOf[map[string]string](custom); !ok || details["role"] != "admin" {
+		t.Errorf("unexpected details %v", details)
+	}
+}
+
+func TestWrappedCauseIsLoggedButNotSent(t *testing.T) {
+	var logs bytes.Buffer
+	output := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(output) })
+
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	GET(engine, "/users", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				return nil, ErrorWithDetails(WrapError(errTestDatabase, ERR_CODE_SERVICE_UNAVAILABLE), testConflict{ExistingID: 7})
+			},
+		}
+	})
+	w := httptest.NewRecorder()
+	engine.GinEngine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	expected := `{"success":false,"error":{"code":"` + ERR_CODE_SERVICE_UNAVAILABLE + `","message":"Service Unavailable","retryable":true,"details":{"existing_id":7}}}`
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != expected {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(logs.String(), "[WARNING] GET /users: Service Unavailable") || !strings.Contains(logs.String(), errTestDatabase.Error()) {
+		t.Fatalf("the cause was not logged: %s", logs.String())
+	}
+}
@@ -0,0 +1,280 @@
+package ginger
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	tag_file = "file"
+
+	rule_max_size  = "max_size"
+	rule_max_count = "max_count"
+	rule_mime      = "mime"
+
+	// multipartOverhead is the room left in a bounded multipart body for the part headers
+	// and the other form fields
+	multipartOverhead = 1 << 20
+)
+
+var (
+	typeOfFileHeader  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	typeOfFileHeaders = reflect.TypeOf([]*multipart.FileHeader(nil))
+
+	fileFieldsCache sync.Map // reflect.Type to []fileField
+)
+
+// fileLimits are declared with the file tag of a *multipart.FileHeader or []*multipart.FileHeader
+// field, e.g. `form:"avatar" file:"max_size=5MB,mime=image/png|image/jpeg"`. A mime ending with
+// /* accepts the whole type, e.g. image/*. max_count bounds the number of files of a slice.
+type fileLimits struct {
+	maxSize    int64
+	maxSizeTag string
+	maxCount   int
+	mimes      []string
+}
+
+func parseFileLimits(tag string) (*fileLimits, error) {
+	limits := &fileLimits{}
+	for _, item := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+		switch key {
+		case "":
+		case rule_max_size:
+			size, err := parseSize(value)
+			if err != nil {
+				return nil, err
+			}
+			limits.maxSize = size
+			limits.maxSizeTag = value
+		case rule_max_count:
+			count, err := strconv.Atoi(value)
+			if err != nil || count <= 0 {
+				return nil, fmt.Errorf("file: invalid count %s", value)
+			}
+			limits.maxCount = count
+		case rule_mime:
+			if value == "" {
+				return nil, errors.New("file: empty mime")
+			}
+			limits.mimes = strings.Split(value, "|")
+		default:
+			return nil, fmt.Errorf("file: unknown limit %s", key)
+		}
+	}
+	return limits, nil
+}
+
+// fileField is a file field of a request, with its limits
+type fileField struct {
+	index    int
+	name     string
+	multiple bool
+	limits   *fileLimits
+}
+
+// fileFieldsOf returns the file fields of the struct t, parsing their file tags once.
+// It panics on a malformed tag, which the routes report when they are registered.
+func fileFieldsOf(t reflect.Type) []fileField {
+	if cached, ok := fileFieldsCache.Load(t); ok {
+		return cached.([]fileField)
+	}
+	fields := make([]fileField, 0)
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup(tag_file)
+		if field.Type != typeOfFileHeader && field.Type != typeOfFileHeaders {
+			if hasTag {
+				panic(fmt.Sprintf("ginger: field %s of %s: file tag on a %s", field.Name, t, field.Type))
+			}
+			continue
+		}
+		name := strings.Split(field.Tag.Get(tag_form), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		limits, err := parseFileLimits(tag)
+		if err != nil {
+			panic(fmt.Sprintf("ginger: field %s of %s: %v", field.Name, t, err))
+		}
+		if limits.maxCount > 0 && field.Type == typeOfFileHeader {
+			panic(fmt.Sprintf("ginger: field %s of %s: max_count on a single file", field.Name, t))
+		}
+		fields = append(fields, fileField{
+			index:    i,
+			name:     name,
+			multiple: field.Type == typeOfFileHeaders,
+			limits:   limits,
+		})
+	}
+	fileFieldsCache.Store(t, fields)
+	return fields
+}
+
+// bodyLimitOf returns the largest multipart body accepted by fields, false when a file is
+// unbounded or when there is no file field
+func bodyLimitOf(fields []fileField) (int64, bool) {
+	if len(fields) == 0 {
+		return 0, false
+	}
+	limit := int64(multipartOverhead)
+	for _, field := range fields {
+		count := 1
+		if field.multiple {
+			count = field.limits.maxCount
+		}
+		if field.limits.maxSize <= 0 || count <= 0 {
+			return 0, false
+		}
+		limit += field.limits.maxSize * int64(count)
+	}
+	return limit, true
+}
+
+// parseSize parses sizes such as 512, 100KB, 5MB or 1GB
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for suffix, size := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if strings.HasSuffix(s, suffix) {
+			s, unit = strings.TrimSuffix(s, suffix), size
+			break
+		}
+	}
+	s = strings.TrimSuffix(s, "B")
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("file: invalid size %s", s)
+	}
+	return n * unit, nil
+}
+
+// check returns the rule and param failed by file, or empty strings
+func (l *fileLimits) check(file *multipart.FileHeader) (string, string, error) {
+	if l.maxSize > 0 && file.Size > l.maxSize {
+		return rule_max_size, l.maxSizeTag, nil
+	}
+	if len(l.mimes) == 0 {
+		return "", "", nil
+	}
+
+	mime, err := sniffContentType(file)
+	if err != nil {
+		return "", "", err
+	}
+	for _, allowed := range l.mimes {
+		if allowed == mime || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mime, strings.TrimSuffix(allowed, "*"))) {
+			return "", "", nil
+		}
+	}
+	return rule_mime, strings.Join(l.mimes, "|"), nil
+}
+
+// sniffContentType detects the mime type from the content rather than trusting the client
+func sniffContentType(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, _ := f.Read(buf)
+	mime, _, _ := strings.Cut(http.DetectContentType(buf[:n]), ";")
+	return mime, nil
+}
+
+func isMultipart(ctx *gin.Context) bool {
+	return ctx.ContentType() == gin.MIMEMultipartPOSTForm
+}
+
+func isForm(ctx *gin.Context) bool {
+	return isMultipart(ctx) || ctx.ContentType() == gin.MIMEPOSTForm
+}
+
+// formValues merges the query with the url encoded or multipart body. When every file field of
+// t is bounded, the multipart body is read up to the sum of their limits, so that an oversized
+// upload is rejected while it streams rather than once it is spooled to disk.
+func formValues(ctx *gin.Context, t reflect.Type) (map[string][]string, error) {
+	if !isForm(ctx) {
+		return ctx.Request.URL.Query(), nil
+	}
+	if isMultipart(ctx) {
+		limit, bounded := bodyLimitOf(fileFieldsOf(t))
+		if bounded && ctx.Request.MultipartForm == nil {
+			ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limit)
+		}
+		_, err := ctx.MultipartForm()
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, &bindingError{field: "body", rule: rule_max_size, param: formatSize(maxBytesErr.Limit)}
+		}
+		if err != nil {
+			return nil, &bindingError{rule: "multipart", param: err.Error()}
+		}
+	} else {
+		err := ctx.Request.ParseForm()
+		if err != nil {
+			return nil, &bindingError{rule: "form", param: err.Error()}
+		}
+	}
+	return ctx.Request.Form, nil
+}
+
+// formatSize formats a size in bytes with the largest unit dividing it, e.g. 5MB
+func formatSize(size int64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}} {
+		if size%unit.size == 0 {
+			return strconv.FormatInt(size/unit.size, 10) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(size, 10) + "B"
+}
+
+// bindFiles sets the file fields of request from the multipart body and checks their limits
+func bindFiles(ctx *gin.Context, request interface{}) error {
+	if !isMultipart(ctx) || ctx.Request.MultipartForm == nil {
+		return nil
+	}
+	files := ctx.Request.MultipartForm.File
+
+	value := reflect.ValueOf(request).Elem()
+	for _, field := range fileFieldsOf(value.Type()) {
+		if len(files[field.name]) == 0 {
+			continue
+		}
+		if field.limits.maxCount > 0 && len(files[field.name]) > field.limits.maxCount {
+			return &bindingError{field: field.name, rule: rule_max_count, param: strconv.Itoa(field.limits.maxCount)}
+		}
+		for _, file := range files[field.name] {
+			rule, param, err := field.limits.check(file)
+			if err != nil {
+				return err
+			}
+			if rule != "" {
+				return &bindingError{field: field.name, rule: rule, param: param}
+			}
+		}
+
+		if field.multiple {
+			value.Field(field.index).Set(reflect.ValueOf(files[field.name]))
+		} else {
+			value.Field(field.index).Set(reflect.ValueOf(files[field.name][0]))
+		}
+	}
+	return nil
+}
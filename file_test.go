@@ -0,0 +1,162 @@
+package ginger
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testUploadRequest struct {
+	Title  string                  `form:"title"`
+	Avatar *multipart.FileHeader   `form:"avatar" file:"max_size=1KB,mime=image/png"`
+	Photos []*multipart.FileHeader `form:"photos" file:"max_size=1KB,max_count=2"`
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+type testPart struct {
+	field   string
+	content []byte
+}
+
+func serveUpload(engine *Engine, parts ...testPart) (*httptest.ResponseRecorder, *Response) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("title", "holidays")
+	for _, part := range parts {
+		w, _ := writer.CreateFormFile(part.field, part.field+".bin")
+		w.Write(part.content)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	engine.GinEngine.ServeHTTP(w, req)
+	resp := &Response{}
+	json.Unmarshal(w.Body.Bytes(), resp)
+	return w, resp
+}
+
+func newUploadEngine(t *testing.T, bound chan<- *testUploadRequest) *Engine {
+	t.Helper()
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	POST(engine, "/upload", func() HandlerResponse[testUploadRequest] {
+		return HandlerResponse[testUploadRequest]{
+			Service: func(ctx *Context[testUploadRequest]) (interface{}, Error) {
+				bound <- ctx.Request
+				return nil, nil
+			},
+		}
+	})
+	return engine
+}
+
+func TestBindFiles(t *testing.T) {
+	bound := make(chan *testUploadRequest, 1)
+	engine := newUploadEngine(t, bound)
+
+	png := append(append([]byte{}, pngHeader...), make([]byte, 100)...)
+	w, _ := serveUpload(engine, testPart{"avatar", png}, testPart{"photos", []byte("a")}, testPart{"photos", []byte("b")})
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	request := <-bound
+	if request.Title != "holidays" || request.Avatar == nil || len(request.Photos) != 2 {
+		t.Fatalf("unexpected request %+v", request)
+	}
+}
+
+func TestBindFilesLimits(t *testing.T) {
+	engine := newUploadEngine(t, make(chan *testUploadRequest, 1))
+
+	tests := []struct {
+		name  string
+		parts []testPart
+		field FieldError
+	}{
+		{
+			name:  "mime",
+			parts: []testPart{{"avatar", []byte("plain text")}},
+			field: FieldError{Field: "avatar", Rule: "mime", Param: "image/png", Message: "avatar must be of type image/png"},
+		},
+		{
+			name:  "max_size",
+			parts: []testPart{{"photos", make([]byte, 2048)}},
+			field: FieldError{Field: "photos", Rule: "max_size", Param: "1KB", Message: "photos must not be larger than 1KB"},
+		},
+		{
+			name:  "max_count",
+			parts: []testPart{{"photos", []byte("a")}, {"photos", []byte("b")}, {"photos", []byte("c")}},
+			field: FieldError{Field: "photos", Rule: "max_count", Param: "2", Message: "photos must not contain more than 2 files"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w, resp := serveUpload(engine, test.parts...)
+			if w.Code != http.StatusBadRequest || resp.Error == nil {
+				t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+			}
+			if len(resp.Error.Fields) != 1 || resp.Error.Fields[0] != test.field {
+				t.Fatalf("expected %+v, got %+v", test.field, resp.Error.Fields)
+			}
+		})
+	}
+}
+
+func TestBindFilesBoundsTheBody(t *testing.T) {
+	engine := newUploadEngine(t, make(chan *testUploadRequest, 1))
+
+	// far beyond the limits of the fields, it is rejected before being spooled to disk
+	w, resp := serveUpload(engine, testPart{"avatar", make([]byte, 4<<20)})
+	if w.Code != http.StatusBadRequest || resp.Error == nil || len(resp.Error.Fields) != 1 {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if field := resp.Error.Fields[0]; field.Field != "body" || field.Rule != "max_size" {
+		t.Fatalf("unexpected field error %+v", field)
+	}
+}
+
+func TestMalformedFileTagPanicsAtRegistration(t *testing.T) {
+	tests := map[string]func(engine *Engine){
+		"unknown limit": func(engine *Engine) {
+			type request struct {
+				Avatar *multipart.FileHeader `form:"avatar" file:"max_sise=1KB"`
+			}
+			POST(engine, "/upload", func() HandlerResponse[request] { return HandlerResponse[request]{} })
+		},
+		"bad size": func(engine *Engine) {
+			type request struct {
+				Avatar *multipart.FileHeader `form:"avatar" file:"max_size=big"`
+			}
+			PUT(engine, "/upload", func() HandlerResponse[request] { return HandlerResponse[request]{} })
+		},
+		"not a file": func(engine *Engine) {
+			type request struct {
+				Name string `form:"name" file:"max_size=1KB"`
+			}
+			SSE(engine, "/upload", func() SSEHandlerResponse[request] { return SSEHandlerResponse[request]{} })
+		},
+		"max_count on a single file": func(engine *Engine) {
+			type request struct {
+				Avatar *multipart.FileHeader `form:"avatar" file:"max_count=2"`
+			}
+			WS(engine, "/upload", func() WSHandlerResponse[request] { return WSHandlerResponse[request]{} })
+		},
+	}
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil || !strings.Contains(r.(string), "Avatar") && !strings.Contains(r.(string), "Name") {
+					t.Fatalf("expected a panic naming the field, got %v", r)
+				}
+			}()
+			register(NewEngine(WithMode(GIN_MODE_TEST)))
+		})
+	}
+}
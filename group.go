@@ -0,0 +1,68 @@
+package ginger
+
+import (
+	"path"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Router is implemented by Engine and Group, and is accepted by the route registration functions
+type Router interface {
+	group() *Group
+}
+
+// Group is a set of routes sharing a route prefix, a middleware chain and an envelope
+type Group struct {
+	engine    *Engine
+	listener  string
+	ginRouter *gin.RouterGroup
+	envelope  Envelope // the one of the engine when nil
+}
+
+// Group creates a group under the root of the engine
+func (e *Engine) Group(prefix string, middleware ...gin.HandlerFunc) *Group {
+	return e.group().Group(prefix, middleware...)
+}
+
+// Group creates a nested group, inheriting the prefix and middleware of g
+func (g *Group) Group(prefix string, middleware ...gin.HandlerFunc) *Group {
+	return &Group{
+		engine:    g.engine,
+		listener:  g.listener,
+		ginRouter: g.ginRouter.Group(prefix, middleware...),
+		envelope:  g.envelope,
+	}
+}
+
+// Use appends middleware to the group
+func (g *Group) Use(middleware ...gin.HandlerFunc) {
+	g.ginRouter.Use(middleware...)
+}
+
+// BasePath returns the full route prefix of the group
+func (g *Group) BasePath() string {
+	return g.ginRouter.BasePath()
+}
+
+func (g *Group) group() *Group {
+	return g
+}
+
+func (e *Engine) group() *Group {
+	return &Group{
+		engine:    e,
+		ginRouter: &e.GinEngine.RouterGroup,
+	}
+}
+
+// fullRoute joins the group prefix and the relative route the same way gin does
+func (g *Group) fullRoute(route string) string {
+	if route == "" {
+		return g.BasePath()
+	}
+	full := path.Join(g.BasePath(), route)
+	if route[len(route)-1] == '/' && full[len(full)-1] != '/' {
+		return full + "/"
+	}
+	return full
+}
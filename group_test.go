@@ -0,0 +1,102 @@
+package ginger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGroupFullRoute(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	api := engine.Group("/api/")
+	v1 := api.Group("v1")
+
+	tests := []struct {
+		group    *Group
+		route    string
+		expected string
+	}{
+		{api, "", "/api/"},
+		{api, "/", "/api/"},
+		{api, "users", "/api/users"},
+		{v1, "", "/api/v1"},
+		{v1, "/users", "/api/v1/users"},
+		{v1, "/users/", "/api/v1/users/"},
+		{v1, "users/:id", "/api/v1/users/:id"},
+	}
+	for _, test := range tests {
+		if full := test.group.fullRoute(test.route); full != test.expected {
+			t.Errorf("%s + %s: expected %s, got %s", test.group.BasePath(), test.route, test.expected, full)
+		}
+	}
+
+	// the routes are recorded as gin registers them
+	GET(v1, "/users/", func() HandlerResponse[struct{}] { return HandlerResponse[struct{}]{} })
+	GET(api.Group("/v2/"), "orders", func() HandlerResponse[struct{}] { return HandlerResponse[struct{}]{} })
+	for i, route := range engine.GinEngine.Routes() {
+		if route.Path != engine.Routes()[i].Path {
+			t.Errorf("gin registered %s, recorded as %s", route.Path, engine.Routes()[i].Path)
+		}
+	}
+}
+
+func TestGroupMiddlewareOrder(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	var calls []string
+	middleware := func(name string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			calls = append(calls, name)
+		}
+	}
+
+	engine.Use(middleware("engine"))
+	api := engine.Group("/api", middleware("api"))
+	v1 := api.Group("/v1", middleware("v1"))
+	v1.Use(middleware("v1 use"))
+	GET(v1, "/users", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				calls = append(calls, "service")
+				return nil, nil
+			},
+		}
+	}, middleware("route"))
+
+	w := httptest.NewRecorder()
+	engine.GinEngine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	expected := []string{"engine", "api", "v1", "v1 use", "route", "service"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected %v, got %v", expected, calls)
+	}
+}
+
+func TestGroupRoutesInTypescript(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	v1 := engine.Group("/api/").Group("v1")
+	GET(v1, "/users", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{Response: []testUser{}}
+	})
+
+	folder := filepath.Join(t.TempDir(), "ts")
+	engine.GenerateTypescript(folder)
+	api, err := os.ReadFile(filepath.Join(folder, "api.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(api), `"/api/v1/users"`) {
+		t.Fatalf("the full route is missing from\n%s", api)
+	}
+}
+
+type testUser struct {
+	Name string `json:"name"`
+}
@@ -1,5 +1,10 @@
 package ginger
 
+import (
+	"reflect"
+	"time"
+)
+
 type Handler[T any] func() HandlerResponse[T]
 
 type HandlerResponse[T any] struct {
@@ -7,10 +12,30 @@ type HandlerResponse[T any] struct {
 	Response   interface{}
 	Pagination bool
 	Sort       bool
+	Raw        bool // the Service returns a *RawResponse, Response being ignored
+	Status     int  // the success status, 200 when zero, which the Service may override with Context.Status
+
+	// Dependencies are the types the Service resolves, checked by Container.Validate at startup,
+	// e.g. []reflect.Type{ginger.Dependency[*UserRepository]()}
+	Dependencies []reflect.Type
+}
+
+type SSEHandler[T any] func() SSEHandlerResponse[T]
+
+type SSEHandlerResponse[T any] struct {
+	Service   SSEService[T]
+	Event     interface{}   // the type of the event data, for the generated client
+	Heartbeat time.Duration // DEFAULT_SSE_HEARTBEAT_INTERVAL when zero
+
+	// Dependencies are the types the Service resolves, see HandlerResponse
+	Dependencies []reflect.Type
 }
 
 type WSHandler[T any] func() WSHandlerResponse[T]
 
 type WSHandlerResponse[T any] struct {
 	Service WSService[T]
+
+	// Dependencies are the types the Service resolves, see HandlerResponse
+	Dependencies []reflect.Type
 }
@@ -0,0 +1,138 @@
+package ginger
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	HEALTH_STATUS_UP   = "up"
+	HEALTH_STATUS_DOWN = "down"
+)
+
+// HealthCheck reports an unhealthy dependency by returning an error
+type HealthCheck func(ctx context.Context) error
+
+type HealthReport struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+type HealthCheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration int64  `json:"duration_ms"`
+}
+
+type namedHealthCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// AddHealthCheck registers a named check that must pass for the engine to be ready
+func (e *Engine) AddHealthCheck(name string, check HealthCheck) {
+	e.healthMu.Lock()
+	defer e.healthMu.Unlock()
+	e.healthChecks = append(e.healthChecks, namedHealthCheck{name: name, check: check})
+}
+
+// HealthCheckDB pings the database behind db
+func HealthCheckDB(db *gorm.DB) HealthCheck {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// HealthCheckCron fails while the cron worker of the engine is not running
+func (e *Engine) HealthCheckCron() HealthCheck {
+	return func(ctx context.Context) error {
+		if !e.cronRunning.Load() {
+			return errors.New("cron worker is not running")
+		}
+		return nil
+	}
+}
+
+// HealthRoutes registers the liveness route HEALTH_LIVE_ROUTE and the readiness route HEALTH_READY_ROUTE.
+// Liveness only tells that the process serves requests, readiness runs the registered checks
+// and fails once graceful shutdown has begun, with ERR_CODE_SERVICE_UNAVAILABLE detailed by
+// the HealthReport.
+func (e *Engine) HealthRoutes(router Router, middleware ...gin.HandlerFunc) {
+	group := router.group()
+	envelope := group.envelopeOf()
+	e.ginHandle(group, http.MethodGet, HEALTH_LIVE_ROUTE, joinMiddlewareAndService(func(c *gin.Context) {
+		c.JSON(http.StatusOK, envelope.Success(&HealthReport{Status: HEALTH_STATUS_UP}, nil))
+	}, middleware...)...)
+	e.ginHandle(group, http.MethodGet, HEALTH_READY_ROUTE, joinMiddlewareAndService(func(c *gin.Context) {
+		c.Set(context_key_engine, e)
+		report := e.Readiness(c.Request.Context())
+		if report.Status == HEALTH_STATUS_UP {
+			c.JSON(http.StatusOK, envelope.Success(report, nil))
+			return
+		}
+		err := ErrorWithDetails(NewError(ERR_CODE_SERVICE_UNAVAILABLE), report)
+		writeError(c, e, envelope, e.Errors.Lookup(err.Code()), err)
+	}, middleware...)...)
+}
+
+// Readiness runs every registered check concurrently, each bounded by HealthCheckTimeout
+func (e *Engine) Readiness(ctx context.Context) *HealthReport {
+	e.healthMu.RLock()
+	checks := make([]namedHealthCheck, len(e.healthChecks))
+	copy(checks, e.healthChecks)
+	e.healthMu.RUnlock()
+
+	report := &HealthReport{
+		Status: HEALTH_STATUS_UP,
+		Checks: make(map[string]HealthCheckResult),
+	}
+	if e.shuttingDown.Load() {
+		report.Status = HEALTH_STATUS_DOWN
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c namedHealthCheck) {
+			defer wg.Done()
+			result := runHealthCheck(ctx, e.HealthCheckTimeout, c.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = result
+			if result.Status != HEALTH_STATUS_UP {
+				report.Status = HEALTH_STATUS_DOWN
+			}
+		}(c)
+	}
+	wg.Wait()
+	return report
+}
+
+func runHealthCheck(ctx context.Context, timeout time.Duration, check HealthCheck) HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeoutOr(timeout, DEFAULT_HEALTH_CHECK_TIMEOUT))
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := HealthCheckResult{
+		Status:   HEALTH_STATUS_UP,
+		Duration: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = HEALTH_STATUS_DOWN
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+package ginger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadinessFailsDuringDrain(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST), WithReadinessDrain(500*time.Millisecond))
+	engine.HealthRoutes(engine)
+
+	addr := freeAddr(t)
+	cancel, done := runEngine(t, engine, addr)
+	if status := getStatus(t, "http://"+addr+HEALTH_READY_ROUTE); status != http.StatusOK {
+		t.Fatalf("expected ready, got %d", status)
+	}
+
+	stoppedAt := time.Now()
+	cancel()
+
+	// readiness fails while the server still answers, until the drain is over
+	var unready time.Time
+	for time.Since(stoppedAt) < 400*time.Millisecond {
+		resp, err := http.Get("http://" + addr + HEALTH_READY_ROUTE)
+		if err != nil {
+			t.Fatalf("server stopped during the drain: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusServiceUnavailable && unready.IsZero() {
+			unready = time.Now()
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if unready.IsZero() {
+		t.Fatal("readiness never failed during the drain")
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(stoppedAt); elapsed < 500*time.Millisecond {
+		t.Fatalf("shut down after %s, before the drain was over", elapsed)
+	}
+}
+
+func TestReadinessErrorResponse(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	engine.AddHealthCheck("db", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	engine.HealthRoutes(engine)
+
+	req := httptest.NewRequest(http.MethodGet, HEALTH_READY_ROUTE, nil)
+	req.Header.Set("Accept-Language", "zh")
+	w := httptest.NewRecorder()
+	engine.GinEngine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", w.Code)
+	}
+	var resp struct {
+		Success bool `json:"success"`
+		Error   struct {
+			Code      string       `json:"code"`
+			Message   string       `json:"message"`
+			Retryable bool         `json:"retryable"`
+			Details   HealthReport `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Success || resp.Error.Code != ERR_CODE_SERVICE_UNAVAILABLE || !resp.Error.Retryable {
+		t.Fatalf("unexpected error %s", w.Body.String())
+	}
+	if resp.Error.Message != "服务不可用" {
+		t.Fatalf("message not localized: %s", resp.Error.Message)
+	}
+	check := resp.Error.Details.Checks["db"]
+	if resp.Error.Details.Status != HEALTH_STATUS_DOWN || check.Error != "connection refused" {
+		t.Fatalf("unexpected details %+v", resp.Error.Details)
+	}
+}
+
+func TestReadinessProblemEnvelope(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST), WithEnvelope(ProblemEnvelope{}))
+	engine.AddHealthCheck("db", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	engine.HealthRoutes(engine)
+
+	w := httptest.NewRecorder()
+	engine.GinEngine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, HEALTH_READY_ROUTE, nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/problem+json" {
+		t.Fatalf("unexpected content type %s", contentType)
+	}
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Status != http.StatusServiceUnavailable || problem.Code != ERR_CODE_SERVICE_UNAVAILABLE {
+		t.Fatalf("unexpected problem %s", w.Body.String())
+	}
+}
+
+func getStatus(t *testing.T, url string) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestHealthCheckTimeoutDefault(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	engine.HealthCheckTimeout = 0
+	engine.AddHealthCheck("db", func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+			return nil
+		}
+	})
+	if report := engine.Readiness(context.Background()); report.Status != HEALTH_STATUS_UP {
+		t.Fatalf("a zero timeout expired the check at once: %+v", report)
+	}
+}
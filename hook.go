@@ -0,0 +1,95 @@
+package ginger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Hook is a lifecycle function of the engine, see Engine.RunContext
+type Hook func(ctx context.Context) error
+
+type hook struct {
+	name string
+	fn   Hook
+}
+
+// OnStart adds a hook run in registration order before the engine accepts requests,
+// e.g. to run migrations or warm caches. An error aborts the startup.
+func (e *Engine) OnStart(name string, fn Hook) {
+	e.onStart = append(e.onStart, hook{name: name, fn: fn})
+}
+
+// OnReady adds a hook run in registration order once the engine accepts requests.
+// An error shuts the engine down.
+func (e *Engine) OnReady(name string, fn Hook) {
+	e.onReady = append(e.onReady, hook{name: name, fn: fn})
+}
+
+// OnShutdown adds a hook run in reverse registration order after the engine stopped serving,
+// e.g. to flush buffers or close database pools. It is skipped when the OnStart hook of the
+// same name did not complete.
+func (e *Engine) OnShutdown(name string, fn Hook) {
+	e.onShutdown = append(e.onShutdown, hook{name: name, fn: fn})
+}
+
+// runStartHooks runs the start hooks in order. On failure the shutdown hooks are run,
+// except those paired with a start hook that did not complete.
+func (e *Engine) runStartHooks(ctx context.Context) error {
+	for i, h := range e.onStart {
+		err := e.runHook(ctx, h)
+		if err != nil {
+			notStarted := make(map[string]bool)
+			for _, h := range e.onStart[i:] {
+				notStarted[h.name] = true
+			}
+			return errors.Join(err, e.runShutdownHooks(ctx, func(name string) bool {
+				return !notStarted[name]
+			}))
+		}
+	}
+	return nil
+}
+
+func (e *Engine) runHooks(ctx context.Context, hooks []hook) error {
+	for _, h := range hooks {
+		err := e.runHook(ctx, h)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Engine) runHook(ctx context.Context, h hook) error {
+	if e.HookTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.HookTimeout)
+		defer cancel()
+	}
+	err := h.fn(ctx)
+	if err != nil {
+		return fmt.Errorf("hook %s: %w", h.name, err)
+	}
+	return nil
+}
+
+// runShutdownHooks runs the shutdown hooks accepted by filter in reverse order, all of them
+// are run even if some fail
+func (e *Engine) runShutdownHooks(ctx context.Context, filter func(name string) bool) error {
+	var err error
+	for i := len(e.onShutdown) - 1; i >= 0; i-- {
+		h := e.onShutdown[i]
+		if !filter(h.name) {
+			continue
+		}
+		if stopErr := h.fn(ctx); stopErr != nil {
+			err = errors.Join(err, fmt.Errorf("hook %s: %w", h.name, stopErr))
+		}
+	}
+	return err
+}
+
+func allHooks(string) bool {
+	return true
+}
@@ -0,0 +1,71 @@
+package ginger
+
+import (
+	"net"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Listener is an extra address served by Run alongside the main one, with its own routes.
+// It is useful to keep operational routes such as health checks off the public address.
+type Listener struct {
+	Name      string
+	Addr      string
+	GinEngine *gin.Engine
+
+	engine *Engine
+}
+
+// Listen adds a named listener on addr, which is host:port or unix:/path/to/socket. It is
+// started and shut down with the main address, and always serves plain http, RunTLS included.
+func (e *Engine) Listen(name string, addr string) *Listener {
+	if _, ok := e.listeners[name]; ok {
+		panic("ginger: listener " + name + " already exists")
+	}
+	l := &Listener{
+		Name:      name,
+		Addr:      addr,
+		GinEngine: e.config.newGinEngine(),
+		engine:    e,
+	}
+	e.listeners[name] = l
+	e.listenerNames = append(e.listenerNames, name)
+	return l
+}
+
+// Listener returns the listener added by Listen, nil if there is none with that name
+func (e *Engine) Listener(name string) *Listener {
+	return e.listeners[name]
+}
+
+func (l *Listener) Use(middleware ...gin.HandlerFunc) {
+	l.GinEngine.Use(middleware...)
+}
+
+func (l *Listener) Group(prefix string, middleware ...gin.HandlerFunc) *Group {
+	return l.group().Group(prefix, middleware...)
+}
+
+func (l *Listener) group() *Group {
+	return &Group{
+		engine:    l.engine,
+		listener:  l.Name,
+		ginRouter: &l.GinEngine.RouterGroup,
+	}
+}
+
+// listen binds addr, removing a stale unix socket file first
+func listen(addr string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(path)
+		}
+		return net.Listen("unix", path)
+	}
+	if addr == "" {
+		addr = ":http"
+	}
+	return net.Listen("tcp", addr)
+}
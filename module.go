@@ -0,0 +1,336 @@
+package ginger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Module registers a set of routes, crons and models as a unit, see Engine.Install
+type Module interface {
+	Name() string
+	Register(e *Engine)
+}
+
+// ModuleDependencies is implemented by a module that must be installed after other modules
+type ModuleDependencies interface {
+	Dependencies() []string
+}
+
+// ModuleStarter is implemented by a module that needs to run code before the engine serves
+type ModuleStarter interface {
+	Start(ctx context.Context) error
+}
+
+// ModuleStopper is implemented by a module that needs to run code when the engine shuts down
+type ModuleStopper interface {
+	Stop(ctx context.Context) error
+}
+
+// DisableModules makes Install skip the modules with the given names
+func (e *Engine) DisableModules(names ...string) {
+	for _, name := range names {
+		e.disabledModules[name] = true
+	}
+}
+
+// Modules returns the names of the installed modules in installation order
+func (e *Engine) Modules() []string {
+	names := make([]string, len(e.modules))
+	copy(names, e.modules)
+	return names
+}
+
+// Install registers the enabled modules ordered by their dependencies. It fails on duplicate
+// module names, missing, disabled or cyclic dependencies, and routes registered twice or
+// conflicting in the gin router.
+// Install is atomic: the routes, crons and generated models of the modules only reach the
+// engine once every module registered, and a failure leaves the engine as it was, except for
+// the middleware added with Use.
+func (e *Engine) Install(modules ...Module) error {
+	if e.installing != nil {
+		return errors.New("module: Install called while installing modules")
+	}
+
+	enabled := make(map[string]Module)
+	names := make([]string, 0, len(modules))
+	for _, m := range modules {
+		name := m.Name()
+		if _, ok := enabled[name]; ok || e.isInstalled(name) {
+			return fmt.Errorf("module %s: installed twice", name)
+		}
+		if e.disabledModules[name] {
+			continue
+		}
+		enabled[name] = m
+		names = append(names, name)
+	}
+
+	for _, name := range names {
+		for _, dep := range dependenciesOf(enabled[name]) {
+			if _, ok := enabled[dep]; ok || e.isInstalled(dep) {
+				continue
+			}
+			if e.disabledModules[dep] {
+				return fmt.Errorf("module %s: depends on disabled module %s", name, dep)
+			}
+			return fmt.Errorf("module %s: depends on missing module %s", name, dep)
+		}
+	}
+
+	ordered, err := sortModules(names, enabled)
+	if err != nil {
+		return err
+	}
+
+	installing := &installation{snapshot: e.snapshot()}
+	e.installing = installing
+	defer func() {
+		e.installing = nil
+		if !installing.done {
+			e.restore(installing.snapshot)
+		}
+	}()
+
+	for _, name := range ordered {
+		m := enabled[name]
+		err := e.registerModule(m)
+		if err != nil {
+			return fmt.Errorf("module %s: %w", name, err)
+		}
+		if starter, ok := m.(ModuleStarter); ok {
+			e.OnStart(name, starter.Start)
+		}
+		if stopper, ok := m.(ModuleStopper); ok {
+			e.OnShutdown(name, stopper.Stop)
+		}
+		e.modules = append(e.modules, name)
+	}
+
+	err = e.checkGinRoutes(installing.routes)
+	if err != nil {
+		return err
+	}
+	installing.done = true
+	for _, fn := range installing.deferred {
+		fn()
+	}
+	return nil
+}
+
+// registerModule turns the duplicate route panics raised while registering into an error,
+// other panics being raised again
+func (e *Engine) registerModule(m Module) (err error) {
+	e.currentModule = m.Name()
+	defer func() {
+		e.currentModule = ""
+		if r := recover(); r != nil {
+			duplicate, ok := r.(*duplicateRouteError)
+			if !ok {
+				panic(r)
+			}
+			err = duplicate
+		}
+	}()
+	m.Register(e)
+	return nil
+}
+
+// installation holds what Install applies once every module registered, and what it restores
+// when one fails
+type installation struct {
+	snapshot engineSnapshot
+	deferred []func()
+	routes   []ginRoute
+	done     bool
+}
+
+// ginRoute is a route added to gin by a module being installed
+type ginRoute struct {
+	module   string
+	listener string
+	method   string
+	path     string
+}
+
+// checkGinRoutes adds routes to copies of the gin routers of the engine, so that the conflicts
+// gin panics on, e.g. /users/:id and /users/:name, fail Install before anything is applied
+func (e *Engine) checkGinRoutes(routes []ginRoute) (err error) {
+	// the copies do not print the debug messages of gin
+	writer, printRoute := gin.DefaultWriter, gin.DebugPrintRouteFunc
+	gin.DefaultWriter, gin.DebugPrintRouteFunc = io.Discard, func(string, string, string, int) {}
+	defer func() {
+		gin.DefaultWriter, gin.DebugPrintRouteFunc = writer, printRoute
+	}()
+
+	scratches := make(map[string]*gin.Engine)
+	for _, route := range routes {
+		scratch, ok := scratches[route.listener]
+		if !ok {
+			scratch = gin.New()
+			for _, existing := range e.ginEngineOf(route.listener).Routes() {
+				scratch.Handle(existing.Method, existing.Path, existing.HandlerFunc)
+			}
+			scratches[route.listener] = scratch
+		}
+		err = handleScratch(scratch, route)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func handleScratch(scratch *gin.Engine, route ginRoute) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("module %s: route %s %s: %v", route.module, route.method, route.path, r)
+		}
+	}()
+	scratch.Handle(route.method, route.path, func(*gin.Context) {})
+	return nil
+}
+
+// ginEngineOf returns the gin engine serving listener, the main one when listener is empty
+func (e *Engine) ginEngineOf(listener string) *gin.Engine {
+	if listener == "" {
+		return e.GinEngine
+	}
+	return e.listeners[listener].GinEngine
+}
+
+// engineSnapshot is the state of the engine that the modules may change while registering
+type engineSnapshot struct {
+	routes        int
+	modules       int
+	onStart       int
+	onReady       int
+	onShutdown    int
+	healthChecks  int
+	listenerNames int
+	container     containerSnapshot
+	errors        errorCatalogSnapshot
+}
+
+func (e *Engine) snapshot() engineSnapshot {
+	e.healthMu.RLock()
+	healthChecks := len(e.healthChecks)
+	e.healthMu.RUnlock()
+	return engineSnapshot{
+		routes:        len(e.routes),
+		modules:       len(e.modules),
+		onStart:       len(e.onStart),
+		onReady:       len(e.onReady),
+		onShutdown:    len(e.onShutdown),
+		healthChecks:  healthChecks,
+		listenerNames: len(e.listenerNames),
+		container:     e.Container.snapshot(),
+		errors:        e.Errors.snapshot(),
+	}
+}
+
+func (e *Engine) restore(s engineSnapshot) {
+	e.routes = e.routes[:s.routes]
+	e.modules = e.modules[:s.modules]
+	e.onStart = e.onStart[:s.onStart]
+	e.onReady = e.onReady[:s.onReady]
+	e.onShutdown = e.onShutdown[:s.onShutdown]
+	e.healthMu.Lock()
+	e.healthChecks = e.healthChecks[:s.healthChecks]
+	e.healthMu.Unlock()
+	for _, name := range e.listenerNames[s.listenerNames:] {
+		delete(e.listeners, name)
+	}
+	e.listenerNames = e.listenerNames[:s.listenerNames]
+	e.Container.restore(s.container)
+	e.Errors.restore(s.errors)
+}
+
+// apply runs fn at once, or after every module registered when called during Install
+func (e *Engine) apply(fn func()) {
+	if e.installing != nil {
+		e.installing.deferred = append(e.installing.deferred, fn)
+		return
+	}
+	fn()
+}
+
+// ginHandle adds a route to the gin router of group, see apply. The route keeps the middleware
+// of the group at the time of the call.
+func (e *Engine) ginHandle(group *Group, method string, route string, handlers ...gin.HandlerFunc) {
+	if e.installing == nil {
+		group.ginRouter.Handle(method, route, handlers...)
+		return
+	}
+	router := group.ginRouter.Group("")
+	e.installing.routes = append(e.installing.routes, ginRoute{
+		module:   e.currentModule,
+		listener: group.listener,
+		method:   method,
+		path:     group.fullRoute(route),
+	})
+	e.apply(func() {
+		router.Handle(method, route, handlers...)
+	})
+}
+
+func (e *Engine) isInstalled(name string) bool {
+	for _, installed := range e.modules {
+		if installed == name {
+			return true
+		}
+	}
+	return false
+}
+
+func dependenciesOf(m Module) []string {
+	if d, ok := m.(ModuleDependencies); ok {
+		return d.Dependencies()
+	}
+	return nil
+}
+
+// sortModules orders modules after their dependencies, keeping the given order otherwise
+func sortModules(names []string, modules map[string]Module) ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	ordered := make([]string, 0, len(names))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("module %s: cyclic dependency %v", name, append(path, name))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range dependenciesOf(modules[name]) {
+			if _, ok := modules[dep]; !ok {
+				continue // installed earlier
+			}
+			err := visit(dep, append(path, name))
+			if err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		ordered = append(ordered, name)
+		return nil
+	}
+
+	for _, name := range names {
+		err := visit(name, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
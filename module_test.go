@@ -0,0 +1,249 @@
+package ginger
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testModule registers a GET route at /<name>, plus what register adds
+type testModule struct {
+	name         string
+	dependencies []string
+	register     func(e *Engine)
+	events       *[]string
+}
+
+func (m *testModule) Name() string {
+	return m.name
+}
+
+func (m *testModule) Dependencies() []string {
+	return m.dependencies
+}
+
+func (m *testModule) Register(e *Engine) {
+	if m.events != nil {
+		*m.events = append(*m.events, "register "+m.name)
+	}
+	GET(e, "/"+m.name, func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				return m.name, nil
+			},
+		}
+	})
+	if m.register != nil {
+		m.register(e)
+	}
+}
+
+func (m *testModule) Start(ctx context.Context) error {
+	*m.events = append(*m.events, "start "+m.name)
+	return nil
+}
+
+func (m *testModule) Stop(ctx context.Context) error {
+	*m.events = append(*m.events, "stop "+m.name)
+	return nil
+}
+
+func TestInstallOrdersModulesByDependencies(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	var events []string
+	err := engine.Install(
+		&testModule{name: "orders", dependencies: []string{"users", "billing"}, events: &events},
+		&testModule{name: "billing", dependencies: []string{"users"}, events: &events},
+		&testModule{name: "users", events: &events},
+		&testModule{name: "audit", events: &events},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"users", "billing", "orders", "audit"}
+	if modules := engine.Modules(); !reflect.DeepEqual(modules, expected) {
+		t.Fatalf("expected %v, got %v", expected, modules)
+	}
+	for _, route := range engine.Routes() {
+		if route.Module != strings.TrimPrefix(route.Path, "/") {
+			t.Fatalf("route %s attributed to module %q", route.Path, route.Module)
+		}
+	}
+
+	if err := engine.runStartHooks(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.runShutdownHooks(context.Background(), allHooks); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{
+		"register users", "register billing", "register orders", "register audit",
+		"start users", "start billing", "start orders", "start audit",
+		"stop audit", "stop orders", "stop billing", "stop users",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected %v, got %v", expected, events)
+	}
+}
+
+func TestInstallRejectsInvalidModules(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules []Module
+		err     string
+	}{
+		{
+			name:    "missing dependency",
+			modules: []Module{&testModule{name: "orders", dependencies: []string{"users"}}},
+			err:     "module orders: depends on missing module users",
+		},
+		{
+			name:    "disabled dependency",
+			modules: []Module{&testModule{name: "orders", dependencies: []string{"legacy"}}, &testModule{name: "legacy"}},
+			err:     "module orders: depends on disabled module legacy",
+		},
+		{
+			name: "cycle",
+			modules: []Module{
+				&testModule{name: "a", dependencies: []string{"b"}},
+				&testModule{name: "b", dependencies: []string{"a"}},
+			},
+			err: "cyclic dependency",
+		},
+		{
+			name:    "installed twice",
+			modules: []Module{&testModule{name: "a"}, &testModule{name: "a"}},
+			err:     "module a: installed twice",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine := NewEngine(WithMode(GIN_MODE_TEST), WithDisabledModules("legacy"))
+			err := engine.Install(test.modules...)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected %q, got %v", test.err, err)
+			}
+			if len(engine.Modules()) != 0 || len(engine.Routes()) != 0 {
+				t.Fatal("a module was installed")
+			}
+		})
+	}
+}
+
+func TestInstallSkipsDisabledModules(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST), WithDisabledModules("legacy"))
+	err := engine.Install(&testModule{name: "users"}, &testModule{name: "legacy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modules := engine.Modules(); !reflect.DeepEqual(modules, []string{"users"}) {
+		t.Fatalf("unexpected modules %v", modules)
+	}
+}
+
+type testService struct{}
+
+func TestInstallIsAtomic(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	if err := engine.Install(&testModule{name: "users"}); err != nil {
+		t.Fatal(err)
+	}
+	var events []string
+	err := engine.Install(
+		&testModule{name: "billing", events: &events, register: func(e *Engine) {
+			Provide[*testService](e.Container, LIFETIME_SINGLETON, func() *testService { return &testService{} })
+			e.RegisterError("6c1e8f5a-1b0d-4c8e-9a47-3f2d5b9e7c10", "Card declined")
+			e.AddHealthCheck("billing", func(ctx context.Context) error { return nil })
+			e.Listen("billing", "127.0.0.1:0")
+			Cron(e, "@every 1s", func() {})
+		}},
+		&testModule{name: "orders", register: func(e *Engine) {
+			GET(e, "/users", func() HandlerResponse[struct{}] {
+				return HandlerResponse[struct{}]{}
+			})
+		}},
+	)
+	if err == nil || !strings.Contains(err.Error(), `route GET /users of module "orders" is already registered by module "users"`) {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if modules := engine.Modules(); !reflect.DeepEqual(modules, []string{"users"}) {
+		t.Fatalf("unexpected modules %v", modules)
+	}
+	if routes := engine.Routes(); len(routes) != 1 {
+		t.Fatalf("unexpected routes %v", routes)
+	}
+	if routes := engine.GinEngine.Routes(); len(routes) != 1 || routes[0].Path != "/users" {
+		t.Fatalf("unexpected gin routes %v", routes)
+	}
+	if len(engine.onStart) != 1 || len(engine.onShutdown) != 1 || len(engine.healthChecks) != 0 {
+		t.Fatal("hooks or health checks were kept")
+	}
+	if engine.Listener("billing") != nil {
+		t.Fatal("the listener was kept")
+	}
+	if len(engine.CronWorker.Entries()) != 0 {
+		t.Fatal("the cron was kept")
+	}
+	if _, ok := engine.Container.provider(reflect.TypeOf(&testService{})); ok {
+		t.Fatal("the provider was kept")
+	}
+	if engine.Errors.Lookup("6c1e8f5a-1b0d-4c8e-9a47-3f2d5b9e7c10").Message != "" {
+		t.Fatal("the error was kept")
+	}
+	if !reflect.DeepEqual(events, []string{"register billing"}) {
+		t.Fatalf("unexpected events %v", events)
+	}
+
+	// the failed modules can be installed once fixed
+	if err := engine.Install(&testModule{name: "billing"}); err != nil {
+		t.Fatal(err)
+	}
+	if routes := engine.GinEngine.Routes(); len(routes) != 2 {
+		t.Fatalf("unexpected gin routes %v", routes)
+	}
+
+	// wildcards conflicting in gin fail Install as well, with the routes already served too
+	GET(engine, "/orders/:id", func() HandlerResponse[struct{}] { return HandlerResponse[struct{}]{} })
+	err = engine.Install(
+		&testModule{name: "a", register: func(e *Engine) {
+			GET(e, "/items/:id", func() HandlerResponse[struct{}] { return HandlerResponse[struct{}]{} })
+		}},
+		&testModule{name: "b", register: func(e *Engine) {
+			Cron(e, "@every 1s", func() {})
+			GET(e, "/items/:name", func() HandlerResponse[struct{}] { return HandlerResponse[struct{}]{} })
+		}},
+	)
+	if err == nil || !strings.Contains(err.Error(), "module b: route GET /items/:name") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	err = engine.Install(&testModule{name: "c", register: func(e *Engine) {
+		GET(e, "/orders/:name", func() HandlerResponse[struct{}] { return HandlerResponse[struct{}]{} })
+	}})
+	if err == nil || !strings.Contains(err.Error(), "module c: route GET /orders/:name") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if modules := engine.Modules(); !reflect.DeepEqual(modules, []string{"users", "billing"}) {
+		t.Fatalf("unexpected modules %v", modules)
+	}
+	if len(engine.Routes()) != 3 || len(engine.GinEngine.Routes()) != 3 || len(engine.CronWorker.Entries()) != 0 {
+		t.Fatalf("the routes or crons of the modules were kept: %v", engine.GinEngine.Routes())
+	}
+}
+
+func TestInstallRaisesOtherPanics(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("expected the panic of the module, got %v", r)
+		}
+		if len(engine.Routes()) != 0 || len(engine.GinEngine.Routes()) != 0 {
+			t.Fatal("the routes of the module were kept")
+		}
+	}()
+	engine.Install(&testModule{name: "users", register: func(e *Engine) {
+		panic("boom")
+	}})
+}
@@ -0,0 +1,79 @@
+package ginger
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ginger-go/ginger/openapi"
+)
+
+// OpenAPI builds a converter holding every JSON and raw route registered so far
+func (e *Engine) OpenAPI() *openapi.Converter {
+	converter := openapi.NewConverter(e.OpenAPIInfo, envelopeOf(e).SuccessModel())
+	for _, status := range e.Errors.statuses() {
+		converter.AddErrorResponse(status, http.StatusText(status))
+	}
+
+	for _, route := range e.routes {
+		if route.Kind != ROUTE_KIND_JSON && route.Kind != ROUTE_KIND_RAW {
+			continue
+		}
+		r := openapi.Route{
+			Method:      route.Method,
+			Path:        route.Path,
+			Request:     route.Request,
+			Response:    route.Response,
+			HandlerName: route.HandlerName,
+			Pagination:  route.Pagination,
+			Sort:        route.Sort,
+			Raw:         route.Kind == ROUTE_KIND_RAW,
+			Status:      route.Status,
+		}
+		if route.Envelope != nil {
+			r.Envelope = route.Envelope.SuccessModel()
+			r.ErrorEnvelope = route.Envelope.ErrorModel()
+			r.ErrorContentType = strings.Split(route.Envelope.ErrorContentType(), ";")[0]
+		}
+		converter.Add(r)
+	}
+	return converter
+}
+
+// GenerateErrorCatalog writes the error catalog of the engine as JSON
+func (e *Engine) GenerateErrorCatalog(filePath string) {
+	data, err := e.Errors.ToJSON()
+	if err != nil {
+		panic(err)
+	}
+
+	err = os.WriteFile(filePath, data, os.ModePerm)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (e *Engine) GenerateOpenAPI(filePath string) {
+	data, err := e.OpenAPI().ToJSON()
+	if err != nil {
+		panic(err)
+	}
+
+	err = os.WriteFile(filePath, data, os.ModePerm)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ServeOpenAPI serves the OpenAPI document at route, e.g. engine.ServeOpenAPI(engine, "/openapi.json")
+func (e *Engine) ServeOpenAPI(router Router, route string, middleware ...gin.HandlerFunc) {
+	e.ginHandle(router.group(), http.MethodGet, route, joinMiddlewareAndService(func(c *gin.Context) {
+		data, err := e.OpenAPI().ToJSON()
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		c.Data(http.StatusOK, "application/json", data)
+	}, middleware...)...)
+}
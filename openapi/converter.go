@@ -0,0 +1,441 @@
+package openapi
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+)
+
+const (
+	tag_uri     = "uri"
+	tag_json    = "json"
+	tag_form    = "form"
+	tag_header  = "header"
+	tag_cookie  = "cookie"
+	tag_binding = "binding"
+
+	content_type_json         = "application/json"
+	content_type_multipart    = "multipart/form-data"
+	content_type_octet_stream = "application/octet-stream"
+)
+
+var typeOfFileHeader = reflect.TypeOf(multipart.FileHeader{})
+
+var (
+	invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+	nonWordChars     = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	anonymousFunc    = regexp.MustCompile(`^func\d+$`)
+)
+
+func NewConverter(info Info, envelope interface{}) *Converter {
+	return &Converter{
+		info:     info,
+		envelope: envelope,
+		errors:   make(map[int]string),
+	}
+}
+
+// Route is the metadata of a registered route needed to describe it as an operation
+type Route struct {
+	Method      string
+	Path        string
+	Request     reflect.Type
+	Response    reflect.Type
+	HandlerName string
+	Pagination  bool
+	Sort        bool
+	Raw         bool // the response is written as is rather than in the envelope
+	Status      int  // the success status, zero for 200
+
+	// Envelope and ErrorEnvelope replace the envelope of the converter for the route, the errors
+	// being written as ErrorContentType, application/json when empty
+	Envelope         interface{}
+	ErrorEnvelope    interface{}
+	ErrorContentType string
+}
+
+// Converter builds an OpenAPI document from routes. Every response body is described
+// as the envelope with its data property replaced by the schema of the route response.
+type Converter struct {
+	info         Info
+	envelope     interface{}
+	errors       map[int]string
+	routes       []Route
+	schemas      map[string]Schema
+	schemaNames  map[reflect.Type]string
+	operationIDs map[string]bool
+}
+
+func (c *Converter) Add(route Route) {
+	c.routes = append(c.routes, route)
+}
+
+// AddErrorResponse documents an error status returned by every operation
+func (c *Converter) AddErrorResponse(status int, description string) {
+	c.errors[status] = description
+}
+
+func (c *Converter) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(c.Document(), "", "  ")
+}
+
+func (c *Converter) Document() *Document {
+	c.schemas = make(map[string]Schema)
+	c.schemaNames = make(map[reflect.Type]string)
+	c.operationIDs = make(map[string]bool)
+	envelope := c.schemaOf(reflect.TypeOf(c.envelope))
+
+	paths := make(map[string]PathItem)
+	for _, route := range c.routes {
+		path := c.convertPath(route.Path)
+		if paths[path] == nil {
+			paths[path] = make(PathItem)
+		}
+		success, failure := envelope, envelope
+		if route.Envelope != nil {
+			success = c.schemaOf(reflect.TypeOf(route.Envelope))
+		}
+		if route.ErrorEnvelope != nil {
+			failure = c.schemaOf(reflect.TypeOf(route.ErrorEnvelope))
+		}
+		op := c.convertToOperation(route, success, failure)
+		if route.Method == http.MethodHead {
+			// the responses to HEAD have the headers of a GET but no content
+			for status, response := range op.Responses {
+				response.Content = nil
+				op.Responses[status] = response
+			}
+		}
+		paths[path][strings.ToLower(route.Method)] = op
+	}
+
+	return &Document{
+		OpenAPI:    VERSION,
+		Info:       c.info,
+		Paths:      paths,
+		Components: Components{Schemas: c.schemas},
+	}
+}
+
+func (c *Converter) convertToOperation(route Route, envelope Schema, errorEnvelope Schema) *Operation {
+	op := &Operation{
+		OperationID: c.operationIDOf(route),
+		Responses:   make(map[string]Response),
+	}
+
+	if route.Request != nil && route.Request.Kind() == reflect.Struct {
+		op.Parameters = append(op.Parameters, c.parametersOf(route.Request, tag_uri, "path")...)
+		upload := c.hasBody(route.Method) && c.hasFiles(route.Request)
+		if !upload {
+			op.Parameters = append(op.Parameters, c.parametersOf(route.Request, tag_form, "query")...)
+		}
+		op.Parameters = append(op.Parameters, c.parametersOf(route.Request, tag_header, "header")...)
+		op.Parameters = append(op.Parameters, c.parametersOf(route.Request, tag_cookie, "cookie")...)
+		if upload {
+			body := c.bodyOf(route.Request, tag_form)
+			_, required := body["required"]
+			op.RequestBody = &RequestBody{
+				Required: required,
+				Content:  map[string]MediaType{content_type_multipart: {Schema: body}},
+			}
+		} else if c.hasBody(route.Method) {
+			body := c.bodyOf(route.Request, tag_json)
+			if body != nil {
+				_, required := body["required"]
+				op.RequestBody = &RequestBody{
+					Required: required,
+					Content:  map[string]MediaType{content_type_json: {Schema: body}},
+				}
+			}
+		}
+	}
+	if route.Pagination {
+		op.Parameters = append(op.Parameters,
+			Parameter{Name: "page", In: "query", Schema: Schema{"type": "integer"}},
+			Parameter{Name: "size", In: "query", Schema: Schema{"type": "integer"}},
+		)
+	}
+	if route.Sort {
+		op.Parameters = append(op.Parameters,
+			Parameter{Name: "by", In: "query", Schema: Schema{"type": "string"}},
+			Parameter{Name: "asc", In: "query", Schema: Schema{"type": "boolean"}},
+		)
+	}
+
+	if route.Raw {
+		op.Responses["200"] = Response{
+			Description: http.StatusText(http.StatusOK),
+			Content:     map[string]MediaType{content_type_octet_stream: {Schema: Schema{"type": "string", "format": "binary"}}},
+		}
+		c.addErrorResponses(op, route, errorEnvelope)
+		return op
+	}
+
+	success := envelope
+	if route.Response != nil {
+		success = Schema{
+			"allOf": []Schema{
+				envelope,
+				{
+					"type":       "object",
+					"properties": map[string]Schema{"data": c.schemaOf(route.Response)},
+				},
+			},
+		}
+	}
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status == http.StatusNoContent || status == http.StatusResetContent || status == http.StatusNotModified {
+		op.Responses[strconv.Itoa(status)] = Response{Description: http.StatusText(status)}
+	} else {
+		op.Responses[strconv.Itoa(status)] = Response{
+			Description: http.StatusText(status),
+			Content:     map[string]MediaType{content_type_json: {Schema: success}},
+		}
+	}
+	c.addErrorResponses(op, route, errorEnvelope)
+	return op
+}
+
+func (c *Converter) addErrorResponses(op *Operation, route Route, envelope Schema) {
+	contentType := route.ErrorContentType
+	if contentType == "" {
+		contentType = content_type_json
+	}
+	for status, description := range c.errors {
+		op.Responses[strconv.Itoa(status)] = Response{
+			Description: description,
+			Content:     map[string]MediaType{contentType: {Schema: envelope}},
+		}
+	}
+}
+
+func (c *Converter) hasBody(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (c *Converter) parametersOf(t reflect.Type, tag string, in string) []Parameter {
+	output := make([]Parameter, 0)
+	for _, field := range c.fieldsOf(t) {
+		name := c.tagName(field, tag)
+		if name == "" {
+			continue
+		}
+		output = append(output, Parameter{
+			Name:     name,
+			In:       in,
+			Required: in == "path" || c.isRequired(field),
+			Schema:   c.schemaOf(field.Type),
+		})
+	}
+	return output
+}
+
+// hasFiles reports whether the request declares multipart file fields
+func (c *Converter) hasFiles(t reflect.Type) bool {
+	for _, field := range c.fieldsOf(t) {
+		ft := field.Type
+		if ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Ptr && ft.Elem() == typeOfFileHeader {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyOf describes the fields of a request tagged with tag, nil if there are none
+func (c *Converter) bodyOf(t reflect.Type, tag string) Schema {
+	properties := make(map[string]Schema)
+	required := make([]string, 0)
+	for _, field := range c.fieldsOf(t) {
+		name := c.tagName(field, tag)
+		if name == "" {
+			continue
+		}
+		properties[name] = c.schemaOf(field.Type)
+		if c.isRequired(field) {
+			required = append(required, name)
+		}
+	}
+	if len(properties) == 0 {
+		return nil
+	}
+	schema := Schema{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (c *Converter) schemaOf(t reflect.Type) Schema {
+	if t == nil {
+		return Schema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return Schema{"type": "string", "format": "date-time"}
+	}
+	if t == typeOfFileHeader {
+		return Schema{"type": "string", "format": "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return Schema{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return Schema{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return Schema{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return Schema{"type": "number", "format": "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": c.schemaOf(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": c.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return c.objectOf(t)
+		}
+		name := c.nameOfSchema(t)
+		if _, ok := c.schemas[name]; !ok {
+			c.schemas[name] = Schema{} // placeholder for recursive types
+			c.schemas[name] = c.objectOf(t)
+		}
+		return Schema{"$ref": "#/components/schemas/" + name}
+	}
+	return Schema{}
+}
+
+func (c *Converter) objectOf(t reflect.Type) Schema {
+	properties := make(map[string]Schema)
+	required := make([]string, 0)
+	for _, field := range c.fieldsOf(t) {
+		name := c.tagName(field, tag_json)
+		if name == "" {
+			if field.Tag.Get(tag_json) == "-" {
+				continue
+			}
+			name = field.Name
+		}
+		properties[name] = c.schemaOf(field.Type)
+		if c.isRequired(field) {
+			required = append(required, name)
+		}
+	}
+	schema := Schema{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fieldsOf lists the exported fields of a struct, flattening embedded structs like gorm.Model
+func (c *Converter) fieldsOf(t reflect.Type) []reflect.StructField {
+	output := make([]reflect.StructField, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get(tag_json) == "" {
+			output = append(output, c.fieldsOf(field.Type)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		output = append(output, field)
+	}
+	return output
+}
+
+func (c *Converter) tagName(field reflect.StructField, tag string) string {
+	name := strings.Split(field.Tag.Get(tag), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func (c *Converter) isRequired(field reflect.StructField) bool {
+	for _, rule := range strings.Split(field.Tag.Get(tag_binding), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// nameOfSchema names a struct after its type, qualified with its package path when another
+// package already has a type of the same name in the document
+func (c *Converter) nameOfSchema(t reflect.Type) string {
+	if name, ok := c.schemaNames[t]; ok {
+		return name
+	}
+	name := invalidNameChars.ReplaceAllString(t.Name(), "_")
+	if _, ok := c.schemas[name]; ok {
+		name = invalidNameChars.ReplaceAllString(strings.ReplaceAll(t.PkgPath(), "/", ".")+"."+t.Name(), "_")
+	}
+	c.schemaNames[t] = name
+	return name
+}
+
+// operationIDOf names an operation after its handler, or after its method and path when the
+// handler is anonymous or the name is already taken, e.g. GET /users/:id is getUsersId
+func (c *Converter) operationIDOf(route Route) string {
+	id := c.nameOfHandler(route.HandlerName)
+	if id == "" || anonymousFunc.MatchString(id) || c.operationIDs[id] {
+		id = strcase.ToLowerCamel(nonWordChars.ReplaceAllString(strings.ToLower(route.Method)+" "+route.Path, " "))
+	}
+	for i, base := 2, id; c.operationIDs[id]; i++ {
+		id = base + strconv.Itoa(i)
+	}
+	c.operationIDs[id] = true
+	return id
+}
+
+func (c *Converter) nameOfHandler(name string) string {
+	xs := strings.Split(name, ".")
+	return strings.TrimSuffix(strcase.ToLowerCamel(xs[len(xs)-1]), "Fm")
+}
+
+// convertPath turns gin route params into OpenAPI path templates, e.g. /users/:id to /users/{id}
+func (c *Converter) convertPath(route string) string {
+	xs := strings.Split(route, "/")
+	for i, x := range xs {
+		if strings.HasPrefix(x, ":") || strings.HasPrefix(x, "*") {
+			xs[i] = "{" + x[1:] + "}"
+		}
+	}
+	return strings.Join(xs, "/")
+}
@@ -0,0 +1,115 @@
+package openapi_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ginger-go/ginger/openapi"
+)
+
+// Info shares its name with openapi.Info
+type Info struct {
+	Name string `json:"name"`
+}
+
+type envelope struct {
+	Data interface{} `json:"data"`
+}
+
+func TestOperationIDsAreUnique(t *testing.T) {
+	c := openapi.NewConverter(openapi.Info{}, envelope{})
+	c.Add(openapi.Route{Method: "GET", Path: "/users/:id", HandlerName: "main.main.func1"})
+	c.Add(openapi.Route{Method: "POST", Path: "/users", HandlerName: "main.main.func1"})
+	c.Add(openapi.Route{Method: "GET", Path: "/orders", HandlerName: "main.(*OrderHandler).List-fm"})
+	c.Add(openapi.Route{Method: "GET", Path: "/products", HandlerName: "main.(*ProductHandler).List-fm"})
+
+	doc := c.Document()
+	ids := map[string]string{
+		"GET /users/{id}": doc.Paths["/users/{id}"]["get"].OperationID,
+		"POST /users":     doc.Paths["/users"]["post"].OperationID,
+		"GET /orders":     doc.Paths["/orders"]["get"].OperationID,
+		"GET /products":   doc.Paths["/products"]["get"].OperationID,
+	}
+	expected := map[string]string{
+		"GET /users/{id}": "getUsersId",
+		"POST /users":     "postUsers",
+		"GET /orders":     "list",
+		"GET /products":   "getProducts",
+	}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("unexpected operation ids %v", ids)
+	}
+}
+
+func TestSchemaNamesAreQualifiedOnCollision(t *testing.T) {
+	c := openapi.NewConverter(openapi.Info{}, envelope{})
+	c.Add(openapi.Route{Method: "GET", Path: "/a", Response: reflect.TypeOf(openapi.Info{})})
+	c.Add(openapi.Route{Method: "GET", Path: "/b", Response: reflect.TypeOf(Info{})})
+
+	doc := c.Document()
+	if _, ok := doc.Components.Schemas["Info"]; !ok {
+		t.Fatal("missing the Info schema")
+	}
+	qualified := "github.com.ginger-go.ginger.openapi_test.Info"
+	if _, ok := doc.Components.Schemas[qualified]; !ok {
+		t.Fatalf("missing %s in %v", qualified, keys(doc.Components.Schemas))
+	}
+
+	// the names stay the same for the whole document
+	ref := doc.Paths["/b"]["get"].Responses["200"].Content["application/json"].Schema
+	if !containsRef(ref, "#/components/schemas/"+qualified) {
+		t.Fatalf("GET /b does not refer to %s: %v", qualified, ref)
+	}
+}
+
+func keys(m map[string]openapi.Schema) []string {
+	output := make([]string, 0, len(m))
+	for k := range m {
+		output = append(output, k)
+	}
+	return output
+}
+
+func containsRef(v interface{}, ref string) bool {
+	switch v := v.(type) {
+	case openapi.Schema:
+		for k, x := range v {
+			if k == "$ref" && x == ref {
+				return true
+			}
+			if containsRef(x, ref) {
+				return true
+			}
+		}
+	case map[string]openapi.Schema:
+		for _, x := range v {
+			if containsRef(x, ref) {
+				return true
+			}
+		}
+	case []openapi.Schema:
+		for _, x := range v {
+			if containsRef(x, ref) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestHeadResponsesHaveNoContent(t *testing.T) {
+	c := openapi.NewConverter(openapi.Info{}, envelope{})
+	c.AddErrorResponse(404, "Not Found")
+	c.Add(openapi.Route{Method: "HEAD", Path: "/users/:id", Response: reflect.TypeOf(Info{})})
+	c.Add(openapi.Route{Method: "GET", Path: "/users/:id", Response: reflect.TypeOf(Info{})})
+
+	doc := c.Document()
+	for status, response := range doc.Paths["/users/{id}"]["head"].Responses {
+		if response.Content != nil {
+			t.Errorf("HEAD documents content for %s", status)
+		}
+	}
+	if doc.Paths["/users/{id}"]["get"].Responses["200"].Content == nil {
+		t.Error("GET lost its content")
+	}
+}
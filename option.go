@@ -0,0 +1,175 @@
+package ginger
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// Option configures an Engine created by NewEngine
+type Option func(*engineConfig)
+
+type engineConfig struct {
+	mode             string
+	trustedProxies   []string
+	trustedPlatform  string
+	remoteIPHeaders  []string
+	logger           gin.HandlerFunc
+	recovery         gin.HandlerFunc
+	wsUpgrader       websocket.Upgrader
+	shutdownTimeout  time.Duration
+	readinessDrain   time.Duration
+	disabledModules  []string
+	cors             *cors.Config
+	localeQuery      string
+	localePreference LocalePreference
+	envelope         Envelope
+}
+
+func newEngineConfig(opts ...Option) *engineConfig {
+	config := &engineConfig{
+		logger:   gin.Logger(),
+		recovery: gin.Recovery(),
+		wsUpgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+		},
+		shutdownTimeout: DEFAULT_SHUTDOWN_TIMEOUT,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// WithMode sets the gin mode, one of GIN_MODE_RELEASE, GIN_MODE_DEBUG or GIN_MODE_TEST
+func WithMode(mode string) Option {
+	return func(c *engineConfig) {
+		c.mode = mode
+	}
+}
+
+// WithTrustedProxies sets the proxies whose forwarding headers are trusted by Context.ClientIP
+func WithTrustedProxies(proxies ...string) Option {
+	return func(c *engineConfig) {
+		c.trustedProxies = proxies
+	}
+}
+
+// WithTrustedPlatform trusts the client ip header set by a platform, e.g. gin.PlatformCloudflare
+func WithTrustedPlatform(platform string) Option {
+	return func(c *engineConfig) {
+		c.trustedPlatform = platform
+	}
+}
+
+// WithRemoteIPHeaders sets the headers used to read the client ip behind a trusted proxy
+func WithRemoteIPHeaders(headers ...string) Option {
+	return func(c *engineConfig) {
+		c.remoteIPHeaders = headers
+	}
+}
+
+// WithLogger replaces the default gin logger, nil disables logging
+func WithLogger(logger gin.HandlerFunc) Option {
+	return func(c *engineConfig) {
+		c.logger = logger
+	}
+}
+
+// WithRecovery replaces the default gin recovery, nil disables recovery
+func WithRecovery(recovery gin.HandlerFunc) Option {
+	return func(c *engineConfig) {
+		c.recovery = recovery
+	}
+}
+
+// WithWebSocketUpgrader sets the upgrader used by WS routes
+func WithWebSocketUpgrader(upgrader websocket.Upgrader) Option {
+	return func(c *engineConfig) {
+		c.wsUpgrader = upgrader
+	}
+}
+
+// WithShutdownTimeout sets Engine.ShutdownTimeout
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(c *engineConfig) {
+		c.shutdownTimeout = timeout
+	}
+}
+
+// WithReadinessDrain sets Engine.ReadinessDrain
+func WithReadinessDrain(drain time.Duration) Option {
+	return func(c *engineConfig) {
+		c.readinessDrain = drain
+	}
+}
+
+// WithCORS adds the CORS middleware to the engine and every listener
+func WithCORS(config cors.Config) Option {
+	return func(c *engineConfig) {
+		c.cors = &config
+	}
+}
+
+// WithDisabledModules makes Engine.Install skip the modules with the given names
+func WithDisabledModules(names ...string) Option {
+	return func(c *engineConfig) {
+		c.disabledModules = append(c.disabledModules, names...)
+	}
+}
+
+// LocalePreference returns the locale preferred by the user of a request, e.g. from its
+// profile, or an empty string when there is none
+type LocalePreference func(c *gin.Context) string
+
+// WithLocaleQuery picks the locale of messages from the query param, e.g. ?lang=zh-TW,
+// before the user preference and Accept-Language
+func WithLocaleQuery(param string) Option {
+	return func(c *engineConfig) {
+		c.localeQuery = param
+	}
+}
+
+// WithLocalePreference picks the locale of messages from the user preference, before
+// Accept-Language
+func WithLocalePreference(preference LocalePreference) Option {
+	return func(c *engineConfig) {
+		c.localePreference = preference
+	}
+}
+
+func (c *engineConfig) newGinEngine() *gin.Engine {
+	if c.mode != "" {
+		gin.SetMode(c.mode)
+	}
+
+	engine := gin.New()
+	if c.logger != nil {
+		engine.Use(c.logger)
+	}
+	if c.recovery != nil {
+		engine.Use(c.recovery)
+	}
+	if c.cors != nil {
+		engine.Use(cors.New(*c.cors))
+	}
+
+	if c.trustedProxies != nil {
+		err := engine.SetTrustedProxies(c.trustedProxies)
+		if err != nil {
+			panic(err)
+		}
+	}
+	if c.trustedPlatform != "" {
+		engine.TrustedPlatform = c.trustedPlatform
+	}
+	if c.remoteIPHeaders != nil {
+		engine.RemoteIPHeaders = c.remoteIPHeaders
+	}
+	return engine
+}
@@ -0,0 +1,86 @@
+package ginger
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RawResponse is returned by a Service to write its result as is instead of in a Response,
+// e.g. for downloads, exports, images or redirects. Set HandlerResponse.Raw so the generated
+// client reads the body as a Blob.
+type RawResponse struct {
+	status      int
+	contentType string
+	reader      io.Reader
+	size        int64
+	file        string
+	filename    string
+	location    string
+}
+
+// RawStream writes reader with contentType, closing it when it is an io.Closer
+func RawStream(contentType string, reader io.Reader) *RawResponse {
+	return &RawResponse{status: http.StatusOK, contentType: contentType, reader: reader, size: -1}
+}
+
+// RawFile serves the file at path, with its content type detected and range requests supported
+func RawFile(path string) *RawResponse {
+	return &RawResponse{status: http.StatusOK, file: path}
+}
+
+// RawRedirect redirects to location with 302 Found
+func RawRedirect(location string) *RawResponse {
+	return &RawResponse{status: http.StatusFound, location: location}
+}
+
+// Attachment makes the client download the content as filename
+func (r *RawResponse) Attachment(filename string) *RawResponse {
+	r.filename = filename
+	return r
+}
+
+// WithStatus replaces the status of a stream or a redirect, files always being served with 200
+// or 206
+func (r *RawResponse) WithStatus(status int) *RawResponse {
+	r.status = status
+	return r
+}
+
+// WithSize sets the Content-Length of a stream
+func (r *RawResponse) WithSize(size int64) *RawResponse {
+	r.size = size
+	return r
+}
+
+// write writes the response, failing before anything is written when a stream has no reader
+func (r *RawResponse) write(c *gin.Context) error {
+	switch {
+	case r.location != "":
+		c.Redirect(r.status, r.location)
+	case r.file != "":
+		if r.filename != "" {
+			c.FileAttachment(r.file, r.filename)
+		} else {
+			c.File(r.file)
+		}
+	default:
+		if r.reader == nil {
+			return errors.New("ginger: raw stream without a reader")
+		}
+		if closer, ok := r.reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+		var headers map[string]string
+		if r.filename != "" {
+			headers = map[string]string{
+				"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": r.filename}),
+			}
+		}
+		c.DataFromReader(r.status, r.size, r.contentType, r.reader, headers)
+	}
+	return nil
+}
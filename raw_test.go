@@ -0,0 +1,50 @@
+package ginger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveRaw(t *testing.T, response *RawResponse) *httptest.ResponseRecorder {
+	t.Helper()
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	GET(engine, "/export", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Raw: true,
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				return response, nil
+			},
+		}
+	})
+	w := httptest.NewRecorder()
+	engine.GinEngine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+	return w
+}
+
+func TestRawStream(t *testing.T) {
+	w := serveRaw(t, RawStream("text/csv", io.NopCloser(strings.NewReader("a,b\n"))).Attachment("export.csv"))
+	if w.Code != http.StatusOK || w.Body.String() != "a,b\n" {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "text/csv" || !strings.Contains(w.Header().Get("Content-Disposition"), "export.csv") {
+		t.Fatalf("unexpected headers %v", w.Header())
+	}
+}
+
+func TestRawStreamWithoutReader(t *testing.T) {
+	w := serveRaw(t, RawStream("text/csv", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d %s", w.Code, w.Body.String())
+	}
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error == nil || resp.Error.Code != ERR_CODE_INTERNAL_SERVER_ERROR {
+		t.Fatalf("unexpected response %s", w.Body.String())
+	}
+}
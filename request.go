@@ -1,34 +1,175 @@
 package ginger
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
-// GinRequest get the request from gin context
+// GinRequest get the request from gin context, ignoring binding and validation errors
 func GinRequest[T any](ctx *gin.Context) *T {
+	request, _ := BindRequest[T](ctx)
+	return request
+}
+
+// BindRequest get the request from gin context, merging the values of the json, form, uri,
+// header and cookie tags, then validates it with the binding tags.
+// The returned error is an ERR_CODE_VALIDATION Error carrying the failed fields.
+func BindRequest[T any](ctx *gin.Context) (*T, Error) {
+	return bindRequest[T](ctx, tag_json, tag_form, tag_uri, tag_header, tag_cookie)
+}
+
+// bindRequest merges the values of the given sources into a T and validates it
+func bindRequest[T any](ctx *gin.Context, sources ...string) (*T, Error) {
 	objects := make([]T, 0)
 	tags := parseTags(new(T))
 
 	for _, tag := range tags {
+		if !contains(sources, tag) {
+			continue
+		}
+		request := new(T)
+		var err error
 		switch tag {
 		case tag_json:
-			request := new(T)
-			ctx.ShouldBindJSON(request)
-			objects = append(objects, *request)
+			if !isForm(ctx) {
+				err = bindJSON(ctx, request)
+			}
 		case tag_form:
-			request := new(T)
-			ctx.ShouldBindQuery(request)
-			objects = append(objects, *request)
+			var values map[string][]string
+			values, err = formValues(ctx, reflect.TypeOf(request).Elem())
+			if err == nil {
+				err = mapForm(request, values, tag_form)
+			}
+			if err == nil {
+				err = bindFiles(ctx, request)
+			}
 		case tag_uri:
-			request := new(T)
-			ctx.ShouldBindUri(request)
-			objects = append(objects, *request)
+			params := make(map[string][]string)
+			for _, p := range ctx.Params {
+				params[p.Key] = []string{p.Value}
+			}
+			err = mapForm(request, params, tag_uri)
+		case tag_header:
+			err = mapForm(request, valuesOfTag[T](tag_header, ctx.Request.Header.Values), tag_header)
+		case tag_cookie:
+			err = mapForm(request, valuesOfTag[T](tag_cookie, func(name string) []string {
+				cookie, err := ctx.Cookie(name)
+				if err != nil {
+					return nil
+				}
+				return []string{cookie}
+			}), tag_cookie)
+		}
+		if err != nil {
+			return nil, validatorOf(engineOf(ctx)).toValidationError(err, requestLocales(ctx))
 		}
+		objects = append(objects, *request)
 	}
 
-	return updateObjectFromObjects(objects)
+	request := updateObjectFromObjects(objects)
+	if request == nil {
+		return nil, nil
+	}
+	v := validatorOf(engineOf(ctx))
+	err := v.ValidateStruct(ctx, request)
+	if err != nil {
+		return request, v.toValidationError(err, requestLocales(ctx))
+	}
+	return request, nil
+}
+
+// requestLocales lists the locales accepted by the client by preference: the locale query param,
+// then the user preference, then Accept-Language
+func requestLocales(ctx *gin.Context) []string {
+	locales := make([]string, 0)
+	if engine := engineOf(ctx); engine != nil {
+		if engine.config.localeQuery != "" {
+			locales = append(locales, parseAcceptLanguage(ctx.Query(engine.config.localeQuery))...)
+		}
+		if engine.config.localePreference != nil {
+			locales = append(locales, parseAcceptLanguage(engine.config.localePreference(ctx))...)
+		}
+	}
+	return append(locales, parseAcceptLanguage(ctx.GetHeader("Accept-Language"))...)
+}
+
+// bindJSON decodes the body, an empty body leaves request untouched
+func bindJSON(ctx *gin.Context, request interface{}) error {
+	if ctx.Request.Body == nil {
+		return nil
+	}
+	err := json.NewDecoder(ctx.Request.Body).Decode(request)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &bindingError{field: typeErr.Field, rule: rule_type, param: typeNameOf(typeErr.Type)}
+	}
+	return &bindingError{rule: rule_json, param: err.Error()}
+}
+
+// mapForm maps values to the fields of request tagged with tag. On failure it maps the values
+// one by one to name the field that does not parse.
+func mapForm(request interface{}, values map[string][]string, tag string) error {
+	err := binding.MapFormWithTag(request, values, tag)
+	if err == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(request).Elem()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		single := map[string][]string{key: values[key]}
+		if binding.MapFormWithTag(reflect.New(t).Interface(), single, tag) == nil {
+			continue
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if strings.Split(field.Tag.Get(tag), ",")[0] == key {
+				return &bindingError{field: nameOfField(field), rule: rule_type, param: typeNameOf(field.Type)}
+			}
+		}
+		return &bindingError{field: key, rule: rule_type}
+	}
+	return &bindingError{rule: rule_type}
+}
+
+// valuesOfTag looks up the values of the fields of T tagged with tag, keyed by the tag name
+func valuesOfTag[T any](tag string, lookup func(name string) []string) map[string][]string {
+	values := make(map[string][]string)
+	t := reflect.TypeOf(new(T)).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get(tag), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if v := lookup(name); len(v) > 0 {
+			values[name] = v
+		}
+	}
+	return values
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
 
 // parseTags get the tags related to the request method
@@ -37,7 +178,7 @@ func parseTags[T any](request T) []string {
 
 	for i := 0; i < reflect.TypeOf(request).Elem().NumField(); i++ {
 		tag := reflect.TypeOf(request).Elem().Field(i).Tag
-		for _, key := range []string{tag_json, tag_form, tag_uri} {
+		for _, key := range []string{tag_json, tag_form, tag_uri, tag_header, tag_cookie} {
 			value := tag.Get(key)
 			if len(value) > 0 {
 				m[key] = true
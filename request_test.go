@@ -0,0 +1,137 @@
+package ginger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testBindRequest struct {
+	ID      int    `uri:"id"`
+	Name    string `json:"name" binding:"required,max=5"`
+	N       int    `form:"n" binding:"omitempty,min=1"`
+	Token   string `header:"X-Token" binding:"required"`
+	Session string `cookie:"session"`
+	Age     int    `json:"age" binding:"gte=0"`
+}
+
+func newBindEngine(t *testing.T, bound chan<- testBindRequest) *Engine {
+	t.Helper()
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	POST(engine, "/users/:id", func() HandlerResponse[testBindRequest] {
+		return HandlerResponse[testBindRequest]{
+			Service: func(ctx *Context[testBindRequest]) (interface{}, Error) {
+				bound <- *ctx.Request
+				return nil, nil
+			},
+		}
+	})
+	return engine
+}
+
+func serveBind(engine *Engine, target string, body string, headers map[string]string) (*httptest.ResponseRecorder, *Response) {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	engine.GinEngine.ServeHTTP(w, req)
+	resp := &Response{}
+	json.Unmarshal(w.Body.Bytes(), resp)
+	return w, resp
+}
+
+func TestBindRequestMergesSources(t *testing.T) {
+	bound := make(chan testBindRequest, 1)
+	engine := newBindEngine(t, bound)
+
+	w, _ := serveBind(engine, "/users/42?n=3", `{"name":"ann","age":30}`, map[string]string{
+		"X-Token": "secret",
+		"Cookie":  "session=abc",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	expected := testBindRequest{ID: 42, Name: "ann", N: 3, Token: "secret", Session: "abc", Age: 30}
+	if request := <-bound; request != expected {
+		t.Fatalf("expected %+v, got %+v", expected, request)
+	}
+}
+
+func TestBindRequestValidationErrors(t *testing.T) {
+	engine := newBindEngine(t, make(chan testBindRequest, 1))
+
+	w, resp := serveBind(engine, "/users/1", `{"name":"too long","age":-1}`, nil)
+	if w.Code != http.StatusBadRequest || resp.Error == nil || resp.Error.Code != ERR_CODE_VALIDATION {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	rules := make(map[string]string)
+	for _, field := range resp.Error.Fields {
+		rules[field.Field] = field.Rule
+		if field.Message == "" {
+			t.Errorf("no message for %s", field.Field)
+		}
+	}
+	expected := map[string]string{"name": "max", "age": "gte", "X-Token": "required"}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("expected %v, got %v", expected, rules)
+	}
+}
+
+func TestBindRequestLocalizesMessages(t *testing.T) {
+	engine := newBindEngine(t, make(chan testBindRequest, 1))
+
+	_, resp := serveBind(engine, "/users/1", `{"name":"ann"}`, map[string]string{"Accept-Language": "zh-TW"})
+	if resp.Error == nil || len(resp.Error.Fields) != 1 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if message := resp.Error.Fields[0].Message; message != "X-Token為必填欄位" {
+		t.Fatalf("message not localized: %s", message)
+	}
+}
+
+func TestBindRequestTypeErrors(t *testing.T) {
+	engine := newBindEngine(t, make(chan testBindRequest, 1))
+	headers := map[string]string{"X-Token": "secret"}
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		field  FieldError
+	}{
+		{
+			name:   "query",
+			target: "/users/1?n=abc",
+			body:   `{"name":"ann"}`,
+			field:  FieldError{Field: "n", Rule: "type", Param: "integer", Message: "n must be a valid integer"},
+		},
+		{
+			name:   "uri",
+			target: "/users/abc",
+			body:   `{"name":"ann"}`,
+			field:  FieldError{Field: "id", Rule: "type", Param: "integer", Message: "id must be a valid integer"},
+		},
+		{
+			name:   "json",
+			target: "/users/1",
+			body:   `{"name":"ann","age":"old"}`,
+			field:  FieldError{Field: "age", Rule: "type", Param: "integer", Message: "age must be a valid integer"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w, resp := serveBind(engine, test.target, test.body, headers)
+			if w.Code != http.StatusBadRequest || resp.Error == nil {
+				t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+			}
+			if len(resp.Error.Fields) != 1 || resp.Error.Fields[0] != test.field {
+				t.Fatalf("expected %+v, got %+v", test.field, resp.Error.Fields)
+			}
+		})
+	}
+}
@@ -8,8 +8,11 @@ type Response struct {
 }
 
 type ResponseError struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	Retryable bool         `json:"retryable,omitempty"`
+	Details   interface{}  `json:"details,omitempty"`
 }
 
 type PaginationResponse struct {
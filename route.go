@@ -0,0 +1,75 @@
+package ginger
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+type RouteKind string
+
+const (
+	ROUTE_KIND_JSON      RouteKind = "json"
+	ROUTE_KIND_WEBSOCKET RouteKind = "websocket"
+	ROUTE_KIND_SSE       RouteKind = "sse"
+	ROUTE_KIND_RAW       RouteKind = "raw"
+)
+
+// RouteInfo describes a route registered through GET, POST, WS and friends
+type RouteInfo struct {
+	Listener    string // empty for the main address
+	Method      string
+	Path        string
+	Kind        RouteKind
+	Request     reflect.Type
+	Response    reflect.Type
+	HandlerName string
+	Pagination  bool
+	Sort        bool
+	Status      int      // the declared success status, zero for 200
+	Envelope    Envelope // the envelope of JSON and raw routes
+	Module      string   // empty when registered outside Engine.Install
+}
+
+// Routes returns the registered routes in registration order
+func (e *Engine) Routes() []RouteInfo {
+	routes := make([]RouteInfo, len(e.routes))
+	copy(routes, e.routes)
+	return routes
+}
+
+// addRoute panics when the route is already registered, naming the modules involved
+func (e *Engine) addRoute(route RouteInfo) {
+	route.Module = e.currentModule
+	for _, r := range e.routes {
+		if r.Listener == route.Listener && r.Method == route.Method && r.Path == route.Path {
+			panic(&duplicateRouteError{route: route, other: r})
+		}
+	}
+	e.routes = append(e.routes, route)
+}
+
+// duplicateRouteError is raised by addRoute, and returned by Install
+type duplicateRouteError struct {
+	route RouteInfo
+	other RouteInfo
+}
+
+func (e *duplicateRouteError) Error() string {
+	return fmt.Sprintf("ginger: route %s %s of module %q is already registered by module %q", e.route.Method, e.route.Path, e.route.Module, e.other.Module)
+}
+
+func typeOfModel(model interface{}) reflect.Type {
+	if model == nil {
+		return nil
+	}
+	t := reflect.TypeOf(model)
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+func nameOfHandler(handler interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+}
@@ -0,0 +1,220 @@
+package ginger
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RunContext starts the cron worker, the http server on addr and every Listener, and blocks
+// until ctx is done or SIGINT/SIGTERM is received. Readiness then fails for ReadinessDrain while
+// requests are still served, after which in-flight requests and running cron jobs are given
+// ShutdownTimeout to finish before it returns. The lifecycle hooks run in this order:
+// OnStart once the addresses are bound, OnReady once they accept requests, and OnShutdown
+// after the servers and the cron worker have stopped.
+func (e *Engine) RunContext(ctx context.Context, addr string) error {
+	return e.run(ctx, addr, true, true, nil)
+}
+
+// RunCronContext is RunContext without the http servers
+func (e *Engine) RunCronContext(ctx context.Context) error {
+	return e.run(ctx, "", false, true, nil)
+}
+
+// server is an http server with the address it is bound to
+type server struct {
+	*http.Server
+	listener net.Listener
+}
+
+// run serves https on addr instead of http when tlsConfig is not nil
+func (e *Engine) run(ctx context.Context, addr string, withServer bool, withCron bool, tlsConfig *tls.Config) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := e.Container.Validate()
+	if err != nil {
+		return err
+	}
+	err = e.Errors.Validate()
+	if err != nil {
+		return err
+	}
+
+	var servers []*server
+	if withServer {
+		servers, err = e.listenAll(addr, tlsConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = e.runStartHooks(ctx)
+	if err != nil {
+		for _, s := range servers {
+			s.listener.Close()
+		}
+		return err
+	}
+
+	if withCron {
+		e.startCron()
+	}
+
+	serveErr := make(chan error, len(servers))
+	for _, s := range servers {
+		go func(s *server) {
+			if s.TLSConfig != nil {
+				serveErr <- s.ServeTLS(s.listener, "", "")
+				return
+			}
+			serveErr <- s.Serve(s.listener)
+		}(s)
+	}
+
+	err = e.runHooks(ctx, e.onReady)
+	if err == nil {
+		select {
+		case err = <-serveErr:
+			if errors.Is(err, http.ErrServerClosed) {
+				err = nil
+			}
+		case <-ctx.Done():
+		}
+	}
+	e.shuttingDown.Store(true)
+	if err == nil && len(servers) > 0 && e.ReadinessDrain > 0 {
+		time.Sleep(e.ReadinessDrain)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeoutOr(e.ShutdownTimeout, DEFAULT_SHUTDOWN_TIMEOUT))
+	defer cancel()
+
+	var wg sync.WaitGroup
+	shutdownErrs := make([]error, len(servers))
+	for i, s := range servers {
+		wg.Add(1)
+		go func(i int, s *server) {
+			defer wg.Done()
+			shutdownErrs[i] = s.Shutdown(shutdownCtx)
+		}(i, s)
+	}
+	wg.Wait()
+
+	err = errors.Join(append([]error{err}, shutdownErrs...)...)
+	if withCron {
+		err = errors.Join(err, e.stopCron(shutdownCtx))
+	}
+	return errors.Join(err, e.runShutdownHooks(shutdownCtx, allHooks))
+}
+
+// timeoutOr returns timeout, or fallback when it is not positive, since an expired context
+// would drop the in-flight work at once
+func timeoutOr(timeout time.Duration, fallback time.Duration) time.Duration {
+	if timeout <= 0 {
+		return fallback
+	}
+	return timeout
+}
+
+// listenAll binds the main address and every Listener, nothing stays bound if one fails
+func (e *Engine) listenAll(addr string, tlsConfig *tls.Config) ([]*server, error) {
+	servers := make([]*server, 0, len(e.listeners)+1)
+	bind := func(addr string, handler http.Handler, tlsConfig *tls.Config) error {
+		ln, err := listen(addr)
+		if err != nil {
+			for _, s := range servers {
+				s.listener.Close()
+			}
+			return err
+		}
+		servers = append(servers, &server{
+			Server: &http.Server{
+				Addr:      addr,
+				Handler:   handler,
+				TLSConfig: tlsConfig,
+			},
+			listener: ln,
+		})
+		return nil
+	}
+
+	err := bind(addr, e.GinEngine, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range e.listenerNames {
+		l := e.listeners[name]
+		err := bind(l.Addr, l.GinEngine, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listener %s: %w", name, err)
+		}
+	}
+	return servers, nil
+}
+
+func (e *Engine) startCron() {
+	e.CronWorker.Start()
+	e.cronRunning.Store(true)
+}
+
+// stopCron stops scheduling new cron jobs and waits for the running ones to return
+func (e *Engine) stopCron(ctx context.Context) error {
+	e.CronWorker.Stop()
+	e.cronRunning.Store(false)
+	return e.cronJobs.wait(ctx)
+}
+
+// jobTracker counts running cron jobs so that shutdown can wait for them
+type jobTracker struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+func (t *jobTracker) wrap(job func()) func() {
+	return func() {
+		if !t.begin() {
+			return
+		}
+		defer t.wg.Done()
+		job()
+	}
+}
+
+func (t *jobTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return false
+	}
+	t.wg.Add(1)
+	return true
+}
+
+func (t *jobTracker) wait(ctx context.Context) error {
+	t.mu.Lock()
+	t.stopped = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
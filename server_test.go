@@ -0,0 +1,342 @@
+package ginger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// freeAddr returns a loopback address that nothing listens on
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// runEngine runs engine on addr until the returned cancel is called, and waits for OnReady
+func runEngine(t *testing.T, engine *Engine, addr string) (context.CancelFunc, <-chan error) {
+	t.Helper()
+	return startEngine(t, engine, func(ctx context.Context) error {
+		return engine.RunContext(ctx, addr)
+	})
+}
+
+// startEngine calls run until the returned cancel is called, and waits for OnReady
+func startEngine(t *testing.T, engine *Engine, run func(ctx context.Context) error) (context.CancelFunc, <-chan error) {
+	t.Helper()
+	ready := make(chan struct{})
+	engine.OnReady("test", func(ctx context.Context) error {
+		close(ready)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx)
+	}()
+
+	select {
+	case <-ready:
+	case err := <-done:
+		cancel()
+		t.Fatalf("engine stopped before ready: %v", err)
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatal("engine not ready")
+	}
+	return cancel, done
+}
+
+func TestRunContextFinishesInFlightRequests(t *testing.T) {
+	// a timeout that is not positive falls back to DEFAULT_SHUTDOWN_TIMEOUT
+	for _, timeout := range []time.Duration{5 * time.Second, 0, -time.Second} {
+		t.Run(timeout.String(), func(t *testing.T) {
+			finishesInFlightRequests(t, timeout)
+		})
+	}
+}
+
+func finishesInFlightRequests(t *testing.T, timeout time.Duration) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST), WithShutdownTimeout(timeout))
+	started := make(chan struct{})
+	GET(engine, "/slow", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				close(started)
+				time.Sleep(300 * time.Millisecond)
+				return "done", nil
+			},
+		}
+	})
+
+	addr := freeAddr(t)
+	cancel, done := runEngine(t, engine, addr)
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- result{status: resp.StatusCode, body: string(body)}
+	}()
+
+	<-started
+	cancel()
+
+	r := <-response
+	if r.err != nil {
+		t.Fatalf("in-flight request failed: %v", r.err)
+	}
+	if r.status != http.StatusOK || r.body != `{"success":true,"data":"done"}` {
+		t.Fatalf("unexpected response %d %s", r.status, r.body)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("RunContext returned %v", err)
+	}
+
+	_, err := http.Get("http://" + addr + "/slow")
+	if err == nil {
+		t.Fatal("server still accepts requests after shutdown")
+	}
+}
+
+func TestRunContextShutdownTimeout(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST), WithShutdownTimeout(100*time.Millisecond))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	GET(engine, "/stuck", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				close(started)
+				<-release
+				return nil, nil
+			},
+		}
+	})
+
+	addr := freeAddr(t)
+	cancel, done := runEngine(t, engine, addr)
+	go http.Get("http://" + addr + "/stuck")
+
+	<-started
+	cancel()
+	if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+}
+
+func TestStopCronWaitsForRunningJobs(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	started := make(chan struct{})
+	finished := false
+	job := engine.cronJobs.wrap(func() {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		finished = true
+	})
+	go job()
+	<-started
+
+	if err := engine.stopCron(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !finished {
+		t.Fatal("stopCron returned before the running job")
+	}
+
+	ran := false
+	engine.cronJobs.wrap(func() { ran = true })()
+	if ran {
+		t.Fatal("a job started after stopCron")
+	}
+}
+
+func TestStopCronTimeout(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	go engine.cronJobs.wrap(func() {
+		close(started)
+		<-release
+	})()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := engine.stopCron(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+}
+
+func testOKService(ctx *Context[struct{}]) (interface{}, Error) {
+	return "ok", nil
+}
+
+func TestRunServesListeners(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	GET(engine, "/ping", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{Service: testOKService}
+	})
+	admin := engine.Listen("admin", freeAddr(t))
+	GET(admin, "/metrics", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{Service: testOKService}
+	})
+
+	addr := freeAddr(t)
+	cancel, done := runEngine(t, engine, addr)
+	for _, url := range []string{"http://" + addr + "/ping", "http://" + admin.Addr + "/metrics"} {
+		if status := getStatus(t, url); status != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", url, status)
+		}
+	}
+	// each address only serves its own routes
+	if status := getStatus(t, "http://"+admin.Addr+"/ping"); status != http.StatusNotFound {
+		t.Fatalf("the listener served a route of the main address: %d", status)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{addr, admin.Addr} {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			t.Fatalf("%s is still served after shutdown", addr)
+		}
+	}
+}
+
+func TestRunFailsWhenAListenerCannotBind(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	engine.Listen("admin", taken.Addr().String())
+	addr := freeAddr(t)
+	err = engine.RunContext(context.Background(), addr)
+	if err == nil || !strings.Contains(err.Error(), "listener admin") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// the main address was released
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("the main address stayed bound: %v", err)
+	}
+	ln.Close()
+}
+
+func TestHooksOrder(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	var events []string
+	record := func(event string) Hook {
+		return func(ctx context.Context) error {
+			events = append(events, event)
+			return nil
+		}
+	}
+	engine.OnStart("db", record("start db"))
+	engine.OnStart("cache", record("start cache"))
+	engine.OnReady("announce", record("ready"))
+	engine.OnShutdown("db", record("stop db"))
+	engine.OnShutdown("cache", record("stop cache"))
+
+	cancel, done := runEngine(t, engine, freeAddr(t))
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"start db", "start cache", "ready", "stop cache", "stop db"}
+	if strings.Join(events, ", ") != strings.Join(expected, ", ") {
+		t.Fatalf("expected %v, got %v", expected, events)
+	}
+}
+
+func TestFailingStartHookAbortsStartup(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	admin := engine.Listen("admin", freeAddr(t))
+	var events []string
+	boom := errors.New("boom")
+	engine.OnStart("db", func(ctx context.Context) error {
+		events = append(events, "start db")
+		return nil
+	})
+	engine.OnStart("cache", func(ctx context.Context) error {
+		events = append(events, "start cache")
+		return boom
+	})
+	engine.OnStart("queue", func(ctx context.Context) error {
+		events = append(events, "start queue")
+		return nil
+	})
+	engine.OnReady("announce", func(ctx context.Context) error {
+		events = append(events, "ready")
+		return nil
+	})
+	for _, name := range []string{"db", "cache", "queue"} {
+		name := name
+		engine.OnShutdown(name, func(ctx context.Context) error {
+			events = append(events, "stop "+name)
+			return nil
+		})
+	}
+
+	addr := freeAddr(t)
+	err := engine.RunContext(context.Background(), addr)
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "hook cache") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// the hooks whose start did not complete are not shut down
+	expected := []string{"start db", "start cache", "stop db"}
+	if strings.Join(events, ", ") != strings.Join(expected, ", ") {
+		t.Fatalf("expected %v, got %v", expected, events)
+	}
+	for _, addr := range []string{addr, admin.Addr} {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Fatalf("%s stayed bound: %v", addr, err)
+		}
+		ln.Close()
+	}
+}
+
+func TestHookTimeout(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	engine.HookTimeout = 50 * time.Millisecond
+	engine.OnStart("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	err := engine.RunContext(context.Background(), freeAddr(t))
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "hook slow") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("the hook was not bounded, took %v", elapsed)
+	}
+}
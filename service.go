@@ -5,3 +5,6 @@ import "github.com/gorilla/websocket"
 type Service[T any] func(ctx *Context[T]) (interface{}, Error)
 
 type WSService[T any] func(ctx *Context[T], ws *websocket.Conn) Error
+
+// SSEService streams events until it returns, or until stream is done
+type SSEService[T any] func(ctx *Context[T], stream *SSEStream) Error
@@ -0,0 +1,162 @@
+package ginger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SSEEvent is a server-sent event, Data being written as JSON
+type SSEEvent struct {
+	Event string        // empty for the default message event
+	ID    string        // becomes the Last-Event-ID of a reconnecting client
+	Retry time.Duration // reconnection delay of the client, zero to leave it as is
+	Data  interface{}
+}
+
+// SSEStream writes the events of an SSE endpoint. It is safe for concurrent use.
+type SSEStream struct {
+	ctx    context.Context
+	writer gin.ResponseWriter
+	mu     sync.Mutex
+}
+
+// Context is cancelled when the client disconnects or the engine shuts down
+func (s *SSEStream) Context() context.Context {
+	return s.ctx
+}
+
+// Done is closed when the stream should stop
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Emit sends data as an event named event
+func (s *SSEStream) Emit(event string, data interface{}) error {
+	return s.Send(SSEEvent{Event: event, Data: data})
+}
+
+// Send writes and flushes event, returning the context error once the stream is closed
+func (s *SSEStream) Send(event SSEEvent) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	if event.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", oneLine(event.ID))
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", oneLine(event.Event))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", event.Retry.Milliseconds())
+	}
+	fmt.Fprintf(&b, "data: %s\n\n", data)
+	return s.write(b.String())
+}
+
+func (s *SSEStream) heartbeat() error {
+	return s.write(":\n\n")
+}
+
+func (s *SSEStream) write(msg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	_, err := s.writer.WriteString(msg)
+	if err != nil {
+		return err
+	}
+	s.writer.Flush()
+	return nil
+}
+
+func oneLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// LastEventID returns the id of the last event received by a reconnecting client
+func LastEventID(c *gin.Context) string {
+	return c.GetHeader("Last-Event-ID")
+}
+
+// serveSSE calls service, the panic of Resolve being returned as an error
+func serveSSE[T any](ctx *Context[T], stream *SSEStream, service SSEService[T]) (err Error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errorOfResolve(r)
+		}
+	}()
+	return service(ctx, stream)
+}
+
+func newGinSSEServiceHandler[T any](engine *Engine, envelope Envelope, handler SSEHandler[T]) gin.HandlerFunc {
+	handlerSetup := handler()
+	interval := handlerSetup.Heartbeat
+	if interval <= 0 {
+		interval = DEFAULT_SSE_HEARTBEAT_INTERVAL
+	}
+	return func(c *gin.Context) {
+		ctx := newContext[T](engine, envelope, c)
+		var err Error
+		ctx.Request, err = BindRequest[T](c)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+
+		streamCtx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+		c.Request = c.Request.WithContext(streamCtx)
+		stream := &SSEStream{ctx: streamCtx, writer: c.Writer}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(200)
+		c.Writer.Flush()
+
+		// the heartbeat keeps proxies from closing an idle stream, and ends it on shutdown
+		// since http.Server.Shutdown waits for the handlers without cancelling them. It is
+		// waited for before returning, gin reusing c.Writer for the next request.
+		heartbeatDone := make(chan struct{})
+		defer func() {
+			cancel()
+			<-heartbeatDone
+		}()
+		go func() {
+			defer close(heartbeatDone)
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-streamCtx.Done():
+					return
+				case <-ticker.C:
+					if engine.shuttingDown.Load() || stream.heartbeat() != nil {
+						cancel()
+						return
+					}
+				}
+			}
+		}()
+
+		err = serveSSE(ctx, stream, handlerSetup.Service)
+		if err != nil {
+			info := errorCatalogOf(engine).Lookup(err.Code())
+			logError(c.Request, info, err)
+			stream.Send(SSEEvent{Event: SSE_EVENT_ERROR, Data: errorBodyOf(c, engine, ctx.envelopeOf(), info, err)})
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package ginger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serveFailingSSE(t *testing.T, opts ...Option) string {
+	t.Helper()
+	engine := NewEngine(append([]Option{WithMode(GIN_MODE_TEST)}, opts...)...)
+	SSE(engine, "/events", func() SSEHandlerResponse[struct{}] {
+		return SSEHandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}], stream *SSEStream) Error {
+				stream.Emit("tick", 1)
+				return NewError(ERR_CODE_SERVICE_UNAVAILABLE)
+			},
+		}
+	})
+	w := httptest.NewRecorder()
+	engine.GinEngine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "event: tick\ndata: 1\n\n") {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	_, data, found := strings.Cut(w.Body.String(), "event: error\ndata: ")
+	if !found {
+		t.Fatalf("no error event in %s", w.Body.String())
+	}
+	return strings.TrimSpace(data)
+}
+
+func TestSSEErrorInResponseEnvelope(t *testing.T) {
+	var resp Response
+	if err := json.Unmarshal([]byte(serveFailingSSE(t)), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Success || resp.Error == nil || resp.Error.Code != ERR_CODE_SERVICE_UNAVAILABLE || resp.Error.Message == "" {
+		t.Fatalf("unexpected error event %+v", resp)
+	}
+}
+
+func TestSSEErrorInProblemEnvelope(t *testing.T) {
+	var problem Problem
+	if err := json.Unmarshal([]byte(serveFailingSSE(t, WithEnvelope(ProblemEnvelope{}))), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Code != ERR_CODE_SERVICE_UNAVAILABLE || problem.Status != http.StatusServiceUnavailable || problem.Title == "" {
+		t.Fatalf("unexpected error event %+v", problem)
+	}
+}
+
+// run with -race: the heartbeat must not write once the handler returned
+func TestSSEHeartbeatStopsWithTheHandler(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	SSE(engine, "/events", func() SSEHandlerResponse[struct{}] {
+		return SSEHandlerResponse[struct{}]{
+			Heartbeat: 50 * time.Microsecond,
+			Service: func(ctx *Context[struct{}], stream *SSEStream) Error {
+				time.Sleep(100 * time.Microsecond)
+				return nil
+			},
+		}
+	})
+	for i := 0; i < 200; i++ {
+		w := httptest.NewRecorder()
+		engine.GinEngine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+		body := w.Body.String()
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected response %d %s", w.Code, body)
+		}
+		time.Sleep(100 * time.Microsecond)
+		if w.Body.String() != body {
+			t.Fatal("the heartbeat wrote after the handler returned")
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package ginger
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSOption configures RunTLS
+type TLSOption func(*tlsOptions)
+
+type tlsOptions struct {
+	clientCAFile   string
+	reloadInterval time.Duration
+}
+
+// WithClientCA requires clients to present a certificate signed by a CA in caFile (mTLS),
+// caFile being reloaded along with the certificate
+func WithClientCA(caFile string) TLSOption {
+	return func(o *tlsOptions) {
+		o.clientCAFile = caFile
+	}
+}
+
+// WithCertReloadInterval sets how often the certificate files are checked for changes,
+// zero or negative disables reloading
+func WithCertReloadInterval(interval time.Duration) TLSOption {
+	return func(o *tlsOptions) {
+		o.reloadInterval = interval
+	}
+}
+
+// RunTLS is Run over https, the certificate is reloaded when certFile, keyFile or the client CA file changes.
+// Only addr is served over https: the listeners added with Listen stay plain http, being meant for
+// internal addresses such as health checks and metrics.
+func (e *Engine) RunTLS(addr string, certFile string, keyFile string, opts ...TLSOption) error {
+	return e.RunTLSContext(context.Background(), addr, certFile, keyFile, opts...)
+}
+
+// RunTLSContext is RunContext over https, the certificate is reloaded when certFile, keyFile or the client CA file changes.
+// The listeners stay plain http, see RunTLS.
+func (e *Engine) RunTLSContext(ctx context.Context, addr string, certFile string, keyFile string, opts ...TLSOption) error {
+	options := &tlsOptions{
+		reloadInterval: DEFAULT_CERT_RELOAD_INTERVAL,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	reloader, err := newCertReloader(certFile, keyFile, options.clientCAFile)
+	if err != nil {
+		return err
+	}
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+	if options.clientCAFile != "" {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.GetConfigForClient = reloader.configForClient(config)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if options.reloadInterval > 0 {
+		go reloader.watch(ctx, options.reloadInterval)
+	}
+	return e.run(ctx, addr, true, true, config)
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("tls: no certificate found in " + caFile)
+	}
+	return pool, nil
+}
+
+// certReloader serves the latest key pair and client CAs found on disk
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTime   time.Time
+}
+
+func newCertReloader(certFile string, keyFile string, caFile string) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+	}
+	_, err := r.reload()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// configForClient returns a GetConfigForClient verifying the clients with the latest CAs
+func (r *certReloader) configForClient(base *tls.Config) func(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		config := base.Clone()
+		config.GetConfigForClient = nil
+		config.ClientCAs = r.clientCAs
+		return config, nil
+	}
+}
+
+// reload loads the key pair and the client CAs if any file changed since the last load
+func (r *certReloader) reload() (bool, error) {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil && !modTime.After(r.modTime)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		clientCAs, err = loadCertPool(r.caFile)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTime = modTime
+	return true, nil
+}
+
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return latest, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *certReloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.reload()
+			if err != nil {
+				log.Println("[WARNING] tls: keep the current certificate, reload failed:", err)
+			} else if reloaded {
+				log.Println("[INFO] tls: certificates reloaded")
+			}
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package ginger
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA signs the certificates of a test
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+var testSerial int64
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber:          nextSerial(),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	key := newTestKey(t)
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the pem encoded certificate and key of a leaf for 127.0.0.1
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: nextSerial(),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	key := newTestKey(t)
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (ca *testCA) clientCert(t *testing.T) tls.Certificate {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func nextSerial() *big.Int {
+	testSerial++
+	return big.NewInt(testSerial)
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// writeFile writes data to path with a modification time later than any previous write
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tlsFixture is a server certificate signed by its CA, written to a temporary folder
+type tlsFixture struct {
+	ca       *testCA
+	certFile string
+	keyFile  string
+}
+
+func newTLSFixture(t *testing.T) *tlsFixture {
+	t.Helper()
+	dir := t.TempDir()
+	f := &tlsFixture{
+		ca:       newTestCA(t, "server ca"),
+		certFile: filepath.Join(dir, "server.crt"),
+		keyFile:  filepath.Join(dir, "server.key"),
+	}
+	f.writeServerCert(t, "server", time.Now())
+	return f
+}
+
+func (f *tlsFixture) writeServerCert(t *testing.T, name string, modTime time.Time) {
+	t.Helper()
+	certPEM, keyPEM := f.ca.issue(t, name, x509.ExtKeyUsageServerAuth)
+	writeFile(t, f.certFile, certPEM, modTime)
+	writeFile(t, f.keyFile, keyPEM, modTime)
+}
+
+func (f *tlsFixture) client(certs ...tls.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(f.ca.cert)
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: pool, Certificates: certs},
+			DisableKeepAlives: true,
+		},
+	}
+}
+
+func newTLSEngine(t *testing.T) *Engine {
+	t.Helper()
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	GET(engine, "/ping", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{
+			Service: func(ctx *Context[struct{}]) (interface{}, Error) {
+				return "pong", nil
+			},
+		}
+	})
+	return engine
+}
+
+func runTLS(t *testing.T, engine *Engine, addr string, f *tlsFixture, opts ...TLSOption) {
+	t.Helper()
+	cancel, done := startEngine(t, engine, func(ctx context.Context) error {
+		return engine.RunTLSContext(ctx, addr, f.certFile, f.keyFile, opts...)
+	})
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestRunTLS(t *testing.T) {
+	f := newTLSFixture(t)
+	addr := freeAddr(t)
+	runTLS(t, newTLSEngine(t), addr, f)
+
+	resp, err := f.client().Get("https://" + addr + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if resp.TLS == nil || resp.TLS.Version < tls.VersionTLS12 {
+		t.Fatal("not served over TLS 1.2 or later")
+	}
+
+	// plain http is refused
+	resp, err = http.Get("http://" + addr + "/ping")
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Fatal("served over plain http")
+		}
+	}
+}
+
+func TestRunTLSRequiresClientCertificate(t *testing.T) {
+	f := newTLSFixture(t)
+	clientCA := newTestCA(t, "client ca")
+	caFile := filepath.Join(t.TempDir(), "client-ca.crt")
+	writeFile(t, caFile, clientCA.pem, time.Now())
+
+	addr := freeAddr(t)
+	runTLS(t, newTLSEngine(t), addr, f, WithClientCA(caFile))
+
+	if _, err := f.client().Get("https://" + addr + "/ping"); err == nil {
+		t.Fatal("a client without certificate was accepted")
+	}
+	if _, err := f.client(newTestCA(t, "other ca").clientCert(t)).Get("https://" + addr + "/ping"); err == nil {
+		t.Fatal("a client certificate of an unknown CA was accepted")
+	}
+
+	resp, err := f.client(clientCA.clientCert(t)).Get("https://" + addr + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestRunTLSReloadsCertificates(t *testing.T) {
+	f := newTLSFixture(t)
+	clientCA := newTestCA(t, "client ca")
+	caFile := filepath.Join(t.TempDir(), "client-ca.crt")
+	start := time.Now()
+	writeFile(t, caFile, clientCA.pem, start)
+
+	addr := freeAddr(t)
+	runTLS(t, newTLSEngine(t), addr, f, WithClientCA(caFile), WithCertReloadInterval(20*time.Millisecond))
+	client := f.client(clientCA.clientCert(t))
+
+	serverName := func() string {
+		resp, err := client.Get("https://" + addr + "/ping")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	if name := serverName(); name != "server" {
+		t.Fatalf("unexpected certificate %s", name)
+	}
+
+	f.writeServerCert(t, "renewed", start.Add(time.Minute))
+	eventually(t, func() bool { return serverName() == "renewed" }, "the server certificate was not reloaded")
+
+	// rotating the client CA rejects the clients of the previous one
+	rotated := newTestCA(t, "rotated client ca")
+	writeFile(t, caFile, rotated.pem, start.Add(2*time.Minute))
+	eventually(t, func() bool {
+		_, err := client.Get("https://" + addr + "/ping")
+		return err != nil
+	}, "the client CA was not reloaded")
+
+	resp, err := f.client(rotated.clientCert(t)).Get("https://" + addr + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func eventually(t *testing.T, condition func() bool, message string) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestRunTLSServesListenersOverHTTP(t *testing.T) {
+	f := newTLSFixture(t)
+	engine := newTLSEngine(t)
+	admin := engine.Listen("admin", freeAddr(t))
+	GET(admin, "/metrics", func() HandlerResponse[struct{}] {
+		return HandlerResponse[struct{}]{Service: testOKService}
+	})
+	addr := freeAddr(t)
+	runTLS(t, engine, addr, f)
+
+	if status := getStatus(t, "http://"+admin.Addr+"/metrics"); status != http.StatusOK {
+		t.Fatalf("expected the listener over plain http, got %d", status)
+	}
+	resp, err := f.client().Get("https://" + addr + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
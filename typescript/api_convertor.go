@@ -2,6 +2,7 @@ package typescript
 
 import (
 	"log"
+	"mime/multipart"
 	"reflect"
 	"runtime"
 	"sort"
@@ -12,7 +13,8 @@ import (
 
 func NewApiConverter() *ApiConverter {
 	return &ApiConverter{
-		apis: make(map[string]Api),
+		apis:         make(map[string]Api),
+		errorDetails: make(map[string]interface{}),
 	}
 }
 
@@ -24,10 +26,14 @@ type Api struct {
 	Handler    interface{}
 	Pagination bool
 	Sort       bool
+	SSE        bool   // Response is then the type of the event data
+	Raw        bool   // the response body is read as a Blob
+	Envelope   string // "problem" when errors are problem details, the Response envelope being the default
 }
 
 type ApiConverter struct {
-	apis map[string]Api
+	apis         map[string]Api
+	errorDetails map[string]interface{}
 }
 
 func (c *ApiConverter) Add(method string, route string, request interface{}, response interface{}, handler interface{}, pagination bool, sort bool) {
@@ -42,6 +48,42 @@ func (c *ApiConverter) Add(method string, route string, request interface{}, res
 	}
 }
 
+// AddSSE records a server-sent events endpoint, event being the type of the event data
+func (c *ApiConverter) AddSSE(route string, request interface{}, event interface{}, handler interface{}) {
+	c.apis["GET:"+route] = Api{
+		Method:   "GET",
+		Route:    route,
+		Request:  request,
+		Response: event,
+		Handler:  handler,
+		SSE:      true,
+	}
+}
+
+// SetEnvelope records the envelope of an endpoint added before
+func (c *ApiConverter) SetEnvelope(method string, route string, envelope string) {
+	if a, ok := c.apis[method+":"+route]; ok {
+		a.Envelope = envelope
+		c.apis[method+":"+route] = a
+	}
+}
+
+// AddRaw records an endpoint writing its response as is rather than in the Response envelope
+func (c *ApiConverter) AddRaw(method string, route string, request interface{}, handler interface{}) {
+	c.apis[method+":"+route] = Api{
+		Method:  method,
+		Route:   route,
+		Request: request,
+		Handler: handler,
+		Raw:     true,
+	}
+}
+
+// AddErrorDetails types the details of the errors of code as the model of details
+func (c *ApiConverter) AddErrorDetails(code string, details interface{}) {
+	c.errorDetails[code] = details
+}
+
 func (c *ApiConverter) ToString() string {
 	output := ""
 
@@ -55,17 +97,45 @@ func (c *ApiConverter) ToString() string {
 		output += c.convertToApi(c.apis[name])
 	}
 
-	return prefix + output
+	return prefix + c.convertErrorDetails() + output
+}
+
+// convertErrorDetails maps the error codes to the types of their details, for detailsOf
+func (c *ApiConverter) convertErrorDetails() string {
+	var codes = make([]string, 0, len(c.errorDetails))
+	for code := range c.errorDetails {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	output := "export interface ErrorDetails {\n"
+	for _, code := range codes {
+		output += "    \"" + code + "\": model." + c.nameOfModel(c.errorDetails[code]) + ";\n"
+	}
+	output += "}\n\n"
+	return output
 }
 
 func (c *ApiConverter) convertToApi(a Api) string {
+	if a.SSE {
+		return c.convertToSSE(a)
+	}
+	if a.Raw {
+		return c.convertToRaw(a)
+	}
 	switch a.Method {
 	case "GET":
-		return c.convertToGet(a)
+		return c.convertToGet(a, "get")
+	case "HEAD":
+		return c.convertToGet(a, "head")
+	case "OPTIONS":
+		return c.convertToGet(a, "options")
 	case "POST":
 		return c.convertToNonGet(a, "post")
 	case "PUT":
 		return c.convertToNonGet(a, "put")
+	case "PATCH":
+		return c.convertToNonGet(a, "patch")
 	case "DELETE":
 		return c.convertToNonGet(a, "del")
 	default:
@@ -74,7 +144,7 @@ func (c *ApiConverter) convertToApi(a Api) string {
 	return ""
 }
 
-func (c *ApiConverter) convertToGet(a Api) string {
+func (c *ApiConverter) convertToGet(a Api, method string) string {
 	if a.Request != nil {
 		uriList := c.getUriList(a.Request)
 		if len(uriList) > 0 {
@@ -100,7 +170,8 @@ func (c *ApiConverter) convertToGet(a Api) string {
 	if a.Sort {
 		param += ", sortBy: string, asc: boolean"
 	}
-	output += param + ", headers?: any): Promise<[Response<"
+	headersParam, headersArg := c.headersOf(a.Request)
+	output += param + ", " + headersParam + "): Promise<[" + c.envelopeOf(a) + "<"
 
 	if a.Response != nil {
 		output += "model." + c.nameOfModel(a.Response)
@@ -108,7 +179,7 @@ func (c *ApiConverter) convertToGet(a Api) string {
 		output += "null"
 	}
 	output += "> | null, number]> => {\n"
-	output += "    return get<"
+	output += "    return " + method + "<"
 	if a.Response != nil {
 		output += "model." + c.nameOfModel(a.Response)
 	} else {
@@ -138,13 +209,18 @@ func (c *ApiConverter) convertToGet(a Api) string {
 		} else {
 			output += ", undefined"
 		}
+	} else {
+		output += ", undefined"
 	}
-	output += ", headers)\n"
+	output += ", " + headersArg + ")\n"
 	output += "}\n\n"
 	return output
 }
 
 func (c *ApiConverter) convertToNonGet(a Api, method string) string {
+	if a.Request != nil && c.hasFiles(a.Request) {
+		return c.convertToMultipart(a)
+	}
 	if a.Request != nil {
 		uriList := c.getUriList(a.Request)
 		if len(uriList) > 0 {
@@ -162,7 +238,8 @@ func (c *ApiConverter) convertToNonGet(a Api, method string) string {
 			output += "req: model." + name + ", "
 		}
 	}
-	output += "headers?: any): Promise<[Response<"
+	headersParam, headersArg := c.headersOf(a.Request)
+	output += headersParam + "): Promise<[" + c.envelopeOf(a) + "<"
 	if a.Response != nil {
 		output += "model." + c.nameOfModel(a.Response)
 	} else {
@@ -182,11 +259,208 @@ func (c *ApiConverter) convertToNonGet(a Api, method string) string {
 	} else {
 		output += ", undefined"
 	}
-	output += ", headers)\n"
+	output += ", " + headersArg + ")\n"
 	output += "}\n\n"
 	return output
 }
 
+// convertToRaw generates a function reading the response body as a Blob, sending the request
+// as query params for GET, HEAD and OPTIONS and as a JSON body otherwise
+func (c *ApiConverter) convertToRaw(a Api) string {
+	uriList := c.getUriList(a.Request)
+	if len(uriList) > 0 {
+		a.Route = c.replaceUri(a.Route, uriList)
+	} else {
+		a.Route += "\""
+	}
+	name := c.nameOfModel(a.Request)
+
+	output := "export const " + c.nameOfFunc(a.Handler) + " = async (host: string, "
+	if len(name) > 0 {
+		output += "req: model." + name + ", "
+	}
+	headersParam, headersArg := c.headersOf(a.Request)
+	output += headersParam + "): Promise<[Blob | null, number, Error | null]> => {\n"
+	output += "    return raw(host, '" + a.Method + "', \"" + a.Route
+
+	switch a.Method {
+	case "GET", "HEAD", "OPTIONS":
+		formList := c.getQueryList(a.Request)
+		if len(formList) > 0 {
+			output += ", [\n"
+			for _, form := range formList {
+				output += "        [\"" + form[0] + "\", req." + form[1] + "],\n"
+			}
+			output += "    ], undefined"
+		} else {
+			output += ", undefined, undefined"
+		}
+	default:
+		if len(name) > 0 {
+			output += ", undefined, req"
+		} else {
+			output += ", undefined, undefined"
+		}
+	}
+	output += ", " + headersArg + ")\n"
+	output += "}\n\n"
+	return output
+}
+
+// convertToSSE generates a function opening an EventSource on the endpoint. EventSource cannot
+// send headers, so header fields of the request are not part of it.
+func (c *ApiConverter) convertToSSE(a Api) string {
+	uriList := c.getUriList(a.Request)
+	if len(uriList) > 0 {
+		a.Route = c.replaceUri(a.Route, uriList)
+	} else {
+		a.Route += "\""
+	}
+	eventType := "any"
+	if a.Response != nil {
+		eventType = "model." + c.nameOfModel(a.Response)
+	}
+
+	output := "export const " + c.nameOfFunc(a.Handler) + " = (host: string, "
+	if name := c.nameOfModel(a.Request); len(name) > 0 {
+		output += "req: model." + name + ", "
+	}
+	output += "options: SSEOptions<" + eventType + ">): EventSource => {\n"
+	output += "    return sse<" + eventType + ">(host, \"" + a.Route
+	formList := c.getQueryList(a.Request)
+	if len(formList) > 0 {
+		output += ", [\n"
+		for _, form := range formList {
+			output += "        [\"" + form[0] + "\", req." + form[1] + "],\n"
+		}
+		output += "    ]"
+	} else {
+		output += ", undefined"
+	}
+	output += ", options)\n"
+	output += "}\n\n"
+	return output
+}
+
+// convertToMultipart generates a function sending the files and the form fields of the request
+// as multipart/form-data
+func (c *ApiConverter) convertToMultipart(a Api) string {
+	uriList := c.getUriList(a.Request)
+	if len(uriList) > 0 {
+		a.Route = c.replaceUri(a.Route, uriList)
+	} else {
+		a.Route += "\""
+	}
+	output := "export const " + c.nameOfFunc(a.Handler) + " = async (host: string, req: model." + c.nameOfModel(a.Request) + ", "
+	headersParam, headersArg := c.headersOf(a.Request)
+	output += headersParam + "): Promise<[" + c.envelopeOf(a) + "<"
+	responseType := "null"
+	if a.Response != nil {
+		responseType = "model." + c.nameOfModel(a.Response)
+	}
+	output += responseType + "> | null, number]> => {\n"
+	output += "    const form = new FormData();\n"
+	for _, form := range c.getQueryList(a.Request) {
+		output += "    _appendForm(form, \"" + form[0] + "\", req." + form[1] + ");\n"
+	}
+	output += "    return multipart<" + responseType + ">(host, '" + a.Method + "', \"" + a.Route + ", form, " + headersArg + ")\n"
+	output += "}\n\n"
+	return output
+}
+
+// hasFiles reports whether the request declares *multipart.FileHeader or []*multipart.FileHeader fields
+func (c *ApiConverter) hasFiles(model interface{}) bool {
+	t := reflect.TypeOf(model)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if isFileType(t.Field(i).Type) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isFileType returns the typescript type of a file field, or an empty string for other fields
+func isFileType(t reflect.Type) string {
+	switch t {
+	case reflect.TypeOf((*multipart.FileHeader)(nil)):
+		return "File"
+	case reflect.TypeOf([]*multipart.FileHeader(nil)):
+		return "File[]"
+	}
+	return ""
+}
+
+// envelopeOf returns the type of the bodies read by the function of a
+func (c *ApiConverter) envelopeOf(a Api) string {
+	if a.Envelope == "problem" {
+		return "ProblemResponse"
+	}
+	return "Response"
+}
+
+// headersOf returns the headers parameter of a function, typed with the header fields of the
+// request, and the arguments passing it to the request helpers. Header values are strings, the
+// undefined ones being left out by the helpers, and the parameter is only required when a header
+// field is. Browsers do not let scripts set the
+// Cookie header, so the cookie fields are not parameters: the helpers send the cookies of the
+// browser instead, with credentials: 'include'.
+func (c *ApiConverter) headersOf(request interface{}) (string, string) {
+	if request == nil {
+		return "headers?: any", "headers"
+	}
+
+	param := "headers?: any"
+	headerList := c.getTaggedList(request, "header")
+	if len(headerList) > 0 {
+		optional := "?"
+		fields := make([]string, 0, len(headerList)+1)
+		for _, item := range headerList {
+			fields = append(fields, "\""+item[0]+"\""+item[1]+": string")
+			if item[1] == "" {
+				optional = ""
+			}
+		}
+		fields = append(fields, "[name: string]: string | undefined")
+		param = "headers" + optional + ": { " + strings.Join(fields, "; ") + " }"
+	}
+
+	if len(c.getTaggedList(request, "cookie")) > 0 {
+		return param, "headers, 'include'"
+	}
+	return param, "headers"
+}
+
+// getTaggedList returns the name and the optional mark of the fields tagged with tag
+func (c *ApiConverter) getTaggedList(model interface{}, tag string) [][]string {
+	output := make([][]string, 0)
+	if reflect.TypeOf(model).Kind() == reflect.Ptr {
+		model = reflect.ValueOf(model).Elem().Interface()
+	}
+	if reflect.TypeOf(model).Kind() != reflect.Struct {
+		return output
+	}
+
+	for i := 0; i < reflect.TypeOf(model).NumField(); i++ {
+		field := reflect.TypeOf(model).Field(i)
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		optional := "?"
+		if strings.Contains(field.Tag.Get("binding"), "required") {
+			optional = ""
+		}
+		output = append(output, []string{name, optional})
+	}
+	return output
+}
+
 func (c *ApiConverter) nameOfModel(model interface{}) string {
 	if reflect.TypeOf(model).Kind() == reflect.Ptr {
 		model = reflect.ValueOf(model).Elem().Interface()
@@ -285,23 +559,80 @@ export interface Pagination {
 export interface Error {
     code: string;
     message: string;
+    fields?: FieldError[];
+    retryable?: boolean;
+    details?: any;
+}
+
+export const detailsOf = <C extends keyof ErrorDetails>(error: Error, code: C): ErrorDetails[C] | undefined => {
+    return error.code === code ? error.details : undefined;
+}
+
+export interface Problem {
+    type: string;
+    title: string;
+    status: number;
+    detail?: string;
+    instance?: string;
+    code: string;
+    fields?: FieldError[];
+    retryable?: boolean;
+    details?: any;
+    [extension: string]: any;
+}
+
+// ProblemResponse is read from the endpoints answering errors as application/problem+json,
+// the problem being turned into the error of the Response
+export type ProblemResponse<T> = Response<T> & { problem?: Problem };
+
+export interface FieldError {
+    field: string;
+    rule: string;
+    param?: string;
+    message?: string;
+}
+
+export const get = async <T>(host: string, url: string, params?: any[][], headers?: any, credentials?: RequestCredentials): Promise<[Response<T> | null, number]> => {
+    return await _withQuery(host, 'GET', url, params, headers, credentials);
+}
+
+export const head = async <T>(host: string, url: string, params?: any[][], headers?: any, credentials?: RequestCredentials): Promise<[Response<T> | null, number]> => {
+    return await _withQuery(host, 'HEAD', url, params, headers, credentials);
+}
+
+export const options = async <T>(host: string, url: string, params?: any[][], headers?: any, credentials?: RequestCredentials): Promise<[Response<T> | null, number]> => {
+    return await _withQuery(host, 'OPTIONS', url, params, headers, credentials);
+}
+
+export const post = async <T>(host: string, url: string, body?: any, headers?: any, credentials?: RequestCredentials): Promise<[Response<T> | null, number]> => {
+    return await _nonGet(host, 'POST', url, body, headers, credentials);
+}
+
+export const put = async <T>(host: string, url: string, body?: any, headers?: any, credentials?: RequestCredentials): Promise<[Response<T> | null, number]> => {
+    return await _nonGet(host, 'PUT', url, body, headers, credentials);
+}
+
+export const patch = async <T>(host: string, url: string, body?: any, headers?: any, credentials?: RequestCredentials): Promise<[Response<T> | null, number]> => {
+    return await _nonGet(host, 'PATCH', url, body, headers, credentials);
+}
+
+export const del = async <T>(host: string, url: string, body?: any, headers?: any, credentials?: RequestCredentials): Promise<[Response<T> | null, number]> => {
+    return await _nonGet(host, 'DELETE', url, body, headers, credentials);
+}
+
+export const upload = async <T>(host: string, url: string, file: File, headers?: any): Promise<[Response<T> | null, number]> => {
+    const formData = new FormData();
+    formData.append('file', file);
+    return await multipart(host, 'POST', url, formData, headers);
 }
 
-export const get = async <T>(host: string, url: string, params?: any[][], headers?: any): Promise<[Response<T> | null, number]> => {
+export const multipart = async <T>(host: string, method: string, url: string, body: FormData, headers?: any, credentials?: RequestCredentials): Promise<[Response<T> | null, number]> => {
     try {
-        url = host + url;
-        if (params) {
-            var li: string[] = []
-            params.map(([key, value]) => {
-                if (key !== undefined && key !== null && value !== undefined && value !== null) {
-                    li.push(key + "=" + value)
-                }
-            })
-            url += '?' + li.join('&')
-        }
-        const response = await fetch(url, {
-            method: 'GET',
-            headers: headers
+        const response = await fetch(host + url, {
+            method: method,
+            headers: _headers(headers),
+            body: body,
+            credentials: credentials,
         });
         return _handleResponse(response);
     } catch (err) {
@@ -310,27 +641,96 @@ export const get = async <T>(host: string, url: string, params?: any[][], header
     }
 }
 
-export const post = async <T>(host: string, url: string, body?: any, headers?: any): Promise<[Response<T> | null, number]> => {
-    return await _nonGet(host, 'POST', url, body, headers);
+// raw reads the body as a Blob, or the error of the response when it failed
+export const raw = async (host: string, method: string, url: string, params?: any[][], body?: any, headers?: any, credentials?: RequestCredentials): Promise<[Blob | null, number, Error | null]> => {
+    try {
+        if (body !== undefined && body !== null) {
+            headers = { ...(headers ?? {}), "Content-Type": "application/json" };
+            body = JSON.stringify(body);
+        }
+        const response = await fetch(host + url + _query(params), {
+            method: method,
+            headers: _headers(headers),
+            body: body,
+            credentials: credentials,
+        });
+        if (!response.ok) {
+            const [resp, status] = await _handleResponse<Response<null>>(response);
+            return [null, status, resp?.error ?? null];
+        }
+        return [await response.blob(), response.status, null];
+    } catch (err) {
+        console.error(err);
+        return [null, 0, null];
+    }
+}
+
+export interface SSEOptions<T> {
+    // handlers by event name, 'message' receiving the events without a name
+    on: Record<string, (data: T, lastEventId: string) => void>;
+    // called with the error sent by the service, which closes the stream, or with null
+    // when the connection fails and the browser retries
+    onError?: (error: Error | null) => void;
+    withCredentials?: boolean;
 }
 
-export const put = async <T>(host: string, url: string, body?: any, headers?: any): Promise<[Response<T> | null, number]> => {
-    return await _nonGet(host, 'PUT', url, body, headers);
+export const sse = <T>(host: string, url: string, params: any[][] | undefined, options: SSEOptions<T>): EventSource => {
+    const source = new EventSource(host + url + _query(params), { withCredentials: options.withCredentials });
+    Object.entries(options.on).map(([event, handler]) => {
+        source.addEventListener(event, (e) => {
+            const message = e as MessageEvent;
+            handler(JSON.parse(message.data), message.lastEventId);
+        });
+    });
+    source.addEventListener('error', (e) => {
+        if (e instanceof MessageEvent) {
+            source.close();
+            options.onError?.(_errorOf(JSON.parse(e.data)));
+        } else {
+            options.onError?.(null);
+        }
+    });
+    return source;
 }
 
-export const del = async <T>(host: string, url: string, body?: any, headers?: any): Promise<[Response<T> | null, number]> => {
-    return await _nonGet(host, 'DELETE', url, body, headers);
+const _query = (params?: any[][]): string => {
+    if (!params) {
+        return '';
+    }
+    var li: string[] = []
+    params.map(([key, value]) => {
+        if (key !== undefined && key !== null && value !== undefined && value !== null) {
+            li.push(key + "=" + value)
+        }
+    })
+    return '?' + li.join('&')
 }
 
-export const upload = async <T>(host: string, url: string, file: File, headers?: any): Promise<[Response<T> | null, number]> => {
+// _headers leaves out the headers without a value, which fetch would send as "undefined"
+const _headers = (headers?: any): Record<string, string> | undefined => {
+    if (headers === undefined || headers === null) {
+        return undefined;
+    }
+    const output: Record<string, string> = {};
+    Object.keys(headers).map((key) => {
+        if (headers[key] !== undefined && headers[key] !== null) {
+            output[key] = headers[key];
+        }
+    });
+    return output;
+}
+
+const _withQuery = async <T>(host: string, method: string, url: string, params?: any[][], headers?: any, credentials?: RequestCredentials): Promise<[Response<T> | null, number]> => {
     try {
-        const formData = new FormData();
-        formData.append('file', file);
-        const response = await fetch(host + url, {
-            method: 'POST',
-            headers: headers,
-            body: formData,
+        url = host + url + _query(params);
+        const response = await fetch(url, {
+            method: method,
+            headers: _headers(headers),
+            credentials: credentials,
         });
+        if (method === 'HEAD') {
+            return [null, response.status];
+        }
         return _handleResponse(response);
     } catch (err) {
         console.error(err);
@@ -338,19 +738,20 @@ export const upload = async <T>(host: string, url: string, file: File, headers?:
     }
 }
 
-const _nonGet = async <T>(host: string, method: string, url: string, body?: any, headers?: any): Promise<[Response<T> | null, number]> => {
+const _nonGet = async <T>(host: string, method: string, url: string, body?: any, headers?: any, credentials?: RequestCredentials): Promise<[Response<T> | null, number]> => {
     try {
         if (headers === undefined || headers === null) {
             headers = {
                 "Content-Type": "application/json",
             }
         } else {
-            headers["Content-Type"] = "application/json";
+            headers = { ...headers, "Content-Type": "application/json" };
         }
         const response = await fetch(host + url, {
             method: method,
-            headers: headers,
+            headers: _headers(headers),
             body: JSON.stringify(body),
+            credentials: credentials,
         });
         return _handleResponse(response);
     } catch (err) {
@@ -359,8 +760,54 @@ const _nonGet = async <T>(host: string, method: string, url: string, body?: any,
     }
 }
 
+const _appendForm = (form: FormData, key: string, value: any) => {
+    if (value === undefined || value === null) {
+        return;
+    }
+    if (Array.isArray(value)) {
+        value.forEach((v) => _appendForm(form, key, v));
+        return;
+    }
+    form.append(key, value instanceof Blob ? value : String(value));
+}
+
+const _problemError = (problem: Problem): Error => {
+    return {
+        code: problem.code,
+        message: problem.detail ?? problem.title,
+        fields: problem.fields,
+        retryable: problem.retryable,
+        details: problem.details,
+    };
+}
+
+// _errorOf reads the error of a body in the Response envelope or a problem
+const _errorOf = (body: any): Error => {
+    if (body.error !== undefined) {
+        return body.error;
+    }
+    if (body.title !== undefined && body.status !== undefined) {
+        return _problemError(body);
+    }
+    return body;
+}
+
 const _handleResponse = async <T>(resp: globalThis.Response): Promise<[T | null, number]> => {
-    if (resp.status === 200) {
+    if (resp.status === 204 || resp.status === 205 || resp.status === 304 || resp.headers.get('Content-Length') === '0') {
+        return [null, resp.status];
+    }
+    if ((resp.headers.get('Content-Type') ?? '').includes('application/problem+json')) {
+        const problem: Problem = await resp.json();
+        return [{
+            success: false,
+            error: _problemError(problem),
+            problem: problem,
+        } as any, resp.status];
+    }
+    if (resp.ok) {
+        return [await resp.json(), resp.status];
+    }
+    if ((resp.headers.get('Content-Type') ?? '').includes('application/json')) {
         return [await resp.json(), resp.status];
     }
     return [null, resp.status];
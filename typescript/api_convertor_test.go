@@ -0,0 +1,145 @@
+package typescript
+
+import (
+	"strings"
+	"testing"
+)
+
+type getUserRequest struct {
+	ID      int    `uri:"id"`
+	Token   string `header:"X-Token" binding:"required"`
+	Limit   int    `header:"X-Limit"`
+	Session string `cookie:"session"`
+}
+
+type listUsersRequest struct {
+	Name  string `form:"name"`
+	Debug bool   `header:"X-Debug"`
+}
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func getUser()   {}
+func listUsers() {}
+func ping()      {}
+
+func TestHeadersAreStrings(t *testing.T) {
+	c := NewApiConverter()
+	c.Add("GET", "/users/:id", getUserRequest{}, user{}, getUser, false, false)
+	c.Add("GET", "/users", listUsersRequest{}, []user{}, listUsers, false, false)
+	output := c.ToString()
+
+	for _, expected := range []string{
+		// a required header makes the parameter required
+		`headers: { "X-Token": string; "X-Limit"?: string; [name: string]: string | undefined }`,
+		// optional otherwise, and booleans are sent as strings too
+		`headers?: { "X-Debug"?: string; [name: string]: string | undefined }`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("missing %s in\n%s", expected, output)
+		}
+	}
+	if strings.Count(output, "headers: headers,") != 0 || !strings.Contains(output, "headers: _headers(headers),") {
+		t.Error("the headers without a value are passed to fetch")
+	}
+	if strings.Contains(output, "& Record<string, string>") {
+		t.Error("the headers are still intersected with Record<string, string>")
+	}
+}
+
+func TestCookiesUseCredentials(t *testing.T) {
+	c := NewApiConverter()
+	c.Add("GET", "/users/:id", getUserRequest{}, user{}, getUser, false, false)
+	c.Add("POST", "/users", listUsersRequest{}, user{}, listUsers, false, false)
+	output := c.ToString()
+
+	if strings.Contains(output, "cookies:") || strings.Contains(output, "Cookie") {
+		t.Errorf("cookies are still set by the client:\n%s", output)
+	}
+	getUserFunc := output[strings.Index(output, "export const getUser"):]
+	getUserFunc = getUserFunc[:strings.Index(getUserFunc, "}\n")]
+	if !strings.Contains(getUserFunc, "headers, 'include')") {
+		t.Errorf("getUser does not send the cookies of the browser:\n%s", getUserFunc)
+	}
+	listUsersFunc := output[strings.Index(output, "export const listUsers"):]
+	listUsersFunc = listUsersFunc[:strings.Index(listUsersFunc, "}\n")]
+	if strings.Contains(listUsersFunc, "'include'") {
+		t.Errorf("listUsers has no cookie field but sends credentials:\n%s", listUsersFunc)
+	}
+}
+
+func TestGetWithoutRequestPassesHeadersAsHeaders(t *testing.T) {
+	c := NewApiConverter()
+	c.Add("GET", "/ping", nil, nil, ping, false, false)
+	output := c.ToString()
+	if !strings.Contains(output, `get<null>(host, "/ping", undefined, headers)`) {
+		t.Errorf("unexpected ping function:\n%s", output)
+	}
+}
+
+func TestRawReturnsTheError(t *testing.T) {
+	c := NewApiConverter()
+	c.AddRaw("GET", "/users/:id", getUserRequest{}, getUser)
+	output := c.ToString()
+
+	for _, expected := range []string{
+		`Promise<[Blob | null, number, Error | null]>`,
+		`const [resp, status] = await _handleResponse<Response<null>>(response);`,
+		`return [null, status, resp?.error ?? null];`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("missing %s in\n%s", expected, output)
+		}
+	}
+}
+
+func TestBodilessStatuses(t *testing.T) {
+	output := NewApiConverter().ToString()
+	if !strings.Contains(output, "resp.status === 204 || resp.status === 205 || resp.status === 304") {
+		t.Errorf("the client reads a body of 204, 205 or 304:\n%s", output)
+	}
+}
+
+func TestSSEReadsTheErrorOfTheEnvelope(t *testing.T) {
+	output := NewApiConverter().ToString()
+	for _, expected := range []string{
+		`options.onError?.(_errorOf(JSON.parse(e.data)));`,
+		`error: _problemError(problem),`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("missing %s in\n%s", expected, output)
+		}
+	}
+}
+
+type patchUserRequest struct {
+	ID   int    `uri:"id"`
+	Name string `json:"name"`
+}
+
+func patchUser()   {}
+func headUser()    {}
+func userOptions() {}
+
+func TestPatchHeadOptionsClients(t *testing.T) {
+	c := NewApiConverter()
+	c.Add("PATCH", "/users/:id", patchUserRequest{}, user{}, patchUser, false, false)
+	c.Add("HEAD", "/users/:id", getUserRequest{}, nil, headUser, false, false)
+	c.Add("OPTIONS", "/users", nil, []user{}, userOptions, false, false)
+	output := c.ToString()
+
+	for _, expected := range []string{
+		"export const patchUser = async (host: string, req: model.patchUserRequest, headers?: any)",
+		"return patch<model.user>(host, \"/users/\" + req.id, req, headers)",
+		"export const headUser = async (host: string, req: model.getUserRequest",
+		"return head<null>(host, \"/users/\" + req.id, undefined, headers, 'include')",
+		"return options<model.user[]>(host, \"/users\", undefined, headers)",
+		"if (method === 'HEAD') {",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("missing %s in\n%s", expected, output)
+		}
+	}
+}
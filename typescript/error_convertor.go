@@ -0,0 +1,61 @@
+package typescript
+
+import (
+	"sort"
+)
+
+func NewErrorConverter() *ErrorConverter {
+	return &ErrorConverter{
+		codes: make(map[string]map[string]string),
+	}
+}
+
+// ErrorConverter generates a const map of the error codes, nested by namespace, so clients
+// can compare error.code with ErrorCodes.billing.CARD_DECLINED rather than with a uuid
+type ErrorConverter struct {
+	codes map[string]map[string]string
+}
+
+func (c *ErrorConverter) Add(namespace string, name string, code string) {
+	if c.codes[namespace] == nil {
+		c.codes[namespace] = make(map[string]string)
+	}
+	c.codes[namespace][name] = code
+}
+
+func (c *ErrorConverter) ToString() string {
+	output := "export const ErrorCodes = {\n"
+	output += c.convertToEntries(c.codes[""], "    ")
+
+	var namespaces = make([]string, 0, len(c.codes))
+	for namespace := range c.codes {
+		if namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		output += "    \"" + namespace + "\": {\n"
+		output += c.convertToEntries(c.codes[namespace], "        ")
+		output += "    },\n"
+	}
+	output += "} as const;\n\n"
+	output += "type _Values<T> = T extends string ? T : { [K in keyof T]: _Values<T[K]> }[keyof T];\n\n"
+	output += "export type ErrorCode = _Values<typeof ErrorCodes>;\n"
+	return output
+}
+
+func (c *ErrorConverter) convertToEntries(codes map[string]string, indent string) string {
+	var names = make([]string, 0, len(codes))
+	for name := range codes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	output := ""
+	for _, name := range names {
+		output += indent + "\"" + name + "\": \"" + codes[name] + "\",\n"
+	}
+	return output
+}
@@ -78,7 +78,9 @@ func (c *ModelConverter) convertToInterface(model any) string {
 		}
 		var fieldType string
 
-		if _, ok := c.typeMap[field.Type.Name()]; ok {
+		if fileType := isFileType(field.Type); fileType != "" {
+			fieldType = fileType
+		} else if _, ok := c.typeMap[field.Type.Name()]; ok {
 			fieldType = c.typeMap[field.Type.Name()]
 		} else if field.Type.Kind() == reflect.Slice {
 			if field.Type.Elem().Kind() == reflect.Struct {
@@ -0,0 +1,112 @@
+package ginger
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	rule_type = "type"
+	rule_json = "json"
+)
+
+// FieldError describes why a request field failed binding or validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// NewValidationError returns an ERR_CODE_VALIDATION error carrying the failed fields
+func NewValidationError(fields ...FieldError) Error {
+	return &validationError{
+		err:    NewError(ERR_CODE_VALIDATION),
+		fields: fields,
+	}
+}
+
+type validationError struct {
+	err    Error
+	fields []FieldError
+}
+
+func (e *validationError) Code() string {
+	return e.err.Code()
+}
+
+func (e *validationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *validationError) Fields() []FieldError {
+	return e.fields
+}
+
+func (e *validationError) Unwrap() error {
+	return e.err
+}
+
+// fieldsOf returns the field errors carried by err, if any
+func fieldsOf(err Error) []FieldError {
+	if v, ok := err.(interface{ Fields() []FieldError }); ok {
+		return v.Fields()
+	}
+	return nil
+}
+
+// bindingError is an error met while decoding a source of the request
+type bindingError struct {
+	field string
+	rule  string
+	param string
+}
+
+func (e *bindingError) Error() string {
+	return e.rule + ": " + e.field + " " + e.param
+}
+
+// typeNameOf names the type expected by a field in the messages, e.g. integer for an int
+func typeNameOf(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Map, reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return "time"
+		}
+		return "object"
+	}
+	return t.String()
+}
+
+// fieldPath drops the struct name from a namespace such as Request.address[0].city,
+// the segments being named by nameOfField
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// nameOfField names a field like the client does, with its json, form, uri, header or cookie tag
+func nameOfField(field reflect.StructField) string {
+	for _, tag := range []string{tag_json, tag_form, tag_uri, tag_header, tag_cookie} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
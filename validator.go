@@ -0,0 +1,248 @@
+package ginger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/ja"
+	"github.com/go-playground/locales/zh"
+	"github.com/go-playground/locales/zh_Hant_TW"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	ja_translations "github.com/go-playground/validator/v10/translations/ja"
+	zh_translations "github.com/go-playground/validator/v10/translations/zh"
+	zh_tw_translations "github.com/go-playground/validator/v10/translations/zh_tw"
+	"golang.org/x/text/language"
+)
+
+// DefaultTranslations registers the messages of the built-in rules for a locale
+type DefaultTranslations func(v *validator.Validate, trans ut.Translator) error
+
+// Validator validates requests with the binding tags and translates the failures.
+// It ships the messages of the built-in rules in en, zh, zh_Hant_TW, ja, es and fr,
+// en being the fallback.
+type Validator struct {
+	mu       sync.RWMutex
+	validate *validator.Validate
+	uni      *ut.UniversalTranslator
+}
+
+var (
+	defaultValidator     *Validator
+	defaultValidatorOnce sync.Once
+)
+
+func NewValidator() *Validator {
+	v := &Validator{
+		validate: validator.New(),
+		uni:      ut.New(en.New()),
+	}
+	v.validate.SetTagName("binding")
+	v.validate.RegisterTagNameFunc(nameOfField)
+
+	for translator, defaults := range map[locales.Translator]DefaultTranslations{
+		en.New():         en_translations.RegisterDefaultTranslations,
+		zh.New():         zh_translations.RegisterDefaultTranslations,
+		zh_Hant_TW.New(): zh_tw_translations.RegisterDefaultTranslations,
+		ja.New():         ja_translations.RegisterDefaultTranslations,
+		es.New():         es_translations.RegisterDefaultTranslations,
+		fr.New():         fr_translations.RegisterDefaultTranslations,
+	} {
+		err := v.AddLocale(translator, defaults)
+		if err != nil {
+			panic(err)
+		}
+	}
+	for tag, message := range bindingMessages {
+		err := v.RegisterMessage("en", tag, message)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return v
+}
+
+// bindingMessages are the en messages of the rules checked while binding, such as the limits
+// of multipart file fields
+var bindingMessages = map[string]string{
+	rule_type:      "{0} must be a valid {1}",
+	rule_max_size:  "{0} must not be larger than {1}",
+	rule_max_count: "{0} must not contain more than {1} files",
+	rule_mime:      "{0} must be of type {1}",
+}
+
+// validatorOf returns the validator of engine, or a shared one when engine is nil
+func validatorOf(engine *Engine) *Validator {
+	if engine != nil && engine.Validator != nil {
+		return engine.Validator
+	}
+	defaultValidatorOnce.Do(func() {
+		defaultValidator = NewValidator()
+	})
+	return defaultValidator
+}
+
+// Engine returns the underlying go-playground validator
+func (v *Validator) Engine() *validator.Validate {
+	return v.validate
+}
+
+// AddLocale adds a language, defaults may be nil when the built-in rules have no messages in it
+func (v *Validator) AddLocale(translator locales.Translator, defaults DefaultTranslations) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	err := v.uni.AddTranslator(translator, true)
+	if err != nil {
+		return err
+	}
+	if defaults == nil {
+		return nil
+	}
+	trans, _ := v.uni.GetTranslator(translator.Locale())
+	return defaults(v.validate, trans)
+}
+
+// RegisterRule adds a binding rule, messages maps a locale such as en or zh_Hant_TW to
+// a template where {0} is the field and {1} the param of the rule, e.g.
+//
+//	v.RegisterRule("phone", isPhone, map[string]string{"en": "{0} must be a phone number"})
+//
+// The context given to fn is the request context, use ResolveContext to reach the container.
+func (v *Validator) RegisterRule(tag string, fn validator.FuncCtx, messages map[string]string) error {
+	v.mu.Lock()
+	err := v.validate.RegisterValidationCtx(tag, fn)
+	v.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for locale, message := range messages {
+		err := v.RegisterMessage(locale, tag, message)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterMessage sets the message template of a rule in a locale, replacing the built-in one
+func (v *Validator) RegisterMessage(locale string, tag string, message string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	trans, found := v.uni.GetTranslator(locale)
+	if !found {
+		return fmt.Errorf("validator: unknown locale %s", locale)
+	}
+	return v.validate.RegisterTranslation(tag, trans, func(trans ut.Translator) error {
+		return trans.Add(tag, message, true)
+	}, func(trans ut.Translator, fe validator.FieldError) string {
+		msg, err := trans.T(tag, fe.Field(), fe.Param())
+		if err != nil {
+			return fe.Error()
+		}
+		return msg
+	})
+}
+
+// ValidateStruct validates obj, ctx is handed to the rules registered with RegisterRule
+func (v *Validator) ValidateStruct(ctx context.Context, obj interface{}) error {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.validate.StructCtx(ctx, obj)
+}
+
+// translate returns the message of fe in the first supported locale, falling back to en
+func (v *Validator) translate(fe validator.FieldError, locales []string) string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	trans, _ := v.uni.FindTranslator(locales...)
+	msg := fe.Translate(trans)
+	if msg == fe.Error() {
+		msg = fe.Translate(v.uni.GetFallback())
+	}
+	return msg
+}
+
+// translateRule returns the message registered for a rule checked outside the validator,
+// or an empty string when there is none
+func (v *Validator) translateRule(rule string, field string, param string, locales []string) string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	trans, _ := v.uni.FindTranslator(locales...)
+	msg, err := trans.T(rule, field, param)
+	if err != nil {
+		msg, err = v.uni.GetFallback().T(rule, field, param)
+	}
+	if err != nil {
+		return ""
+	}
+	return msg
+}
+
+// toValidationError converts the error of binding or validating a request into a validation error,
+// with the messages in the first supported locale
+func (v *Validator) toValidationError(err error, locales []string) Error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			fields[i] = FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Rule:    fe.Tag(),
+				Param:   fe.Param(),
+				Message: v.translate(fe, locales),
+			}
+		}
+		return NewValidationError(fields...)
+	}
+
+	var bindingErr *bindingError
+	if errors.As(err, &bindingErr) {
+		return NewValidationError(FieldError{
+			Field:   bindingErr.field,
+			Rule:    bindingErr.rule,
+			Param:   bindingErr.param,
+			Message: v.translateRule(bindingErr.rule, bindingErr.field, bindingErr.param, locales),
+		})
+	}
+	return NewValidationError(FieldError{Rule: rule_type})
+}
+
+// parseAcceptLanguage lists the locales of an Accept-Language header by preference, each tag
+// expanded from the most to the least specific, e.g. zh-TW gives zh_Hant_TW, zh_TW, zh_Hant, zh
+func parseAcceptLanguage(header string) []string {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return nil
+	}
+	output := make([]string, 0)
+	for _, tag := range tags {
+		output = append(output, expandLocale(tag)...)
+	}
+	return output
+}
+
+func expandLocale(tag language.Tag) []string {
+	base, _ := tag.Base()
+	script, _ := tag.Script()
+	region, _ := tag.Region()
+	return []string{
+		strings.Join([]string{base.String(), script.String(), region.String()}, "_"),
+		base.String() + "_" + region.String(),
+		base.String() + "_" + script.String(),
+		base.String(),
+	}
+}
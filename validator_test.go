@@ -0,0 +1,72 @@
+package ginger
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type testEmailRepository struct {
+	taken map[string]bool
+}
+
+type testSignUpRequest struct {
+	Email string `json:"email" binding:"required,email,unique_email"`
+}
+
+func TestCustomRuleAtRequestTime(t *testing.T) {
+	engine := NewEngine(WithMode(GIN_MODE_TEST))
+	ProvideValue(engine.Container, &testEmailRepository{taken: map[string]bool{"ann@example.com": true}})
+
+	err := engine.Validator.RegisterRule("unique_email", func(ctx context.Context, fl validator.FieldLevel) bool {
+		repository, err := ResolveContext[*testEmailRepository](ctx)
+		if err != nil {
+			t.Errorf("the rule cannot reach the container: %v", err)
+			return false
+		}
+		return !repository.taken[fl.Field().String()]
+	}, map[string]string{
+		"en": "{0} is already registered",
+		"fr": "{0} est déjà enregistré",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	POST(engine, "/sign-up", func() HandlerResponse[testSignUpRequest] {
+		return HandlerResponse[testSignUpRequest]{
+			Service: func(ctx *Context[testSignUpRequest]) (interface{}, Error) {
+				return ctx.Request.Email, nil
+			},
+		}
+	})
+
+	tests := []struct {
+		name     string
+		body     string
+		language string
+		status   int
+		message  string
+	}{
+		{name: "free", body: `{"email":"bob@example.com"}`, status: http.StatusOK},
+		{name: "taken", body: `{"email":"ann@example.com"}`, language: "en-US", status: http.StatusBadRequest, message: "email is already registered"},
+		{name: "taken in fr", body: `{"email":"ann@example.com"}`, language: "fr-FR, en;q=0.5", status: http.StatusBadRequest, message: "email est déjà enregistré"},
+		{name: "built-in rules first", body: `{"email":"ann"}`, language: "en", status: http.StatusBadRequest, message: "email must be a valid email address"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w, resp := serveBind(engine, "/sign-up", test.body, map[string]string{"Accept-Language": test.language})
+			if w.Code != test.status {
+				t.Fatalf("expected %d, got %d %s", test.status, w.Code, w.Body.String())
+			}
+			if test.message == "" {
+				return
+			}
+			if resp.Error == nil || len(resp.Error.Fields) != 1 || resp.Error.Fields[0].Message != test.message {
+				t.Fatalf("expected %q, got %s", test.message, w.Body.String())
+			}
+		})
+	}
+}